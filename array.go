@@ -0,0 +1,114 @@
+package lucene_to_sql
+
+import (
+	"fmt"
+	"strings"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+	"github.com/zhuliquan/lucene_parser/term"
+)
+
+// arrayQueryToSql converts term on array column, the query is matched when any element of array matches the term.
+func (c *SqlConvertor) arrayQueryToSql(
+	field string, tType *esMapping.Property, value *term.Term,
+) (string, error) {
+	termType := getTermType(value)
+	if termType&(term.SINGLE_TERM_TYPE|term.PHRASE_TERM_TYPE) != 0 &&
+		termType&(term.WILDCARD_TERM_TYPE|term.FUZZY_TERM_TYPE) == 0 &&
+		!esMapping.CheckTextType(tType.Type) {
+		if sql, ok, err := c.arrayContainsToSql(field, tType, value); err != nil || ok {
+			return sql, err
+		}
+	}
+
+	elem := c.arrayElement()
+	sql, err := c.valueQueryToSql(elem, tType, value)
+	if err != nil {
+		return "", err
+	}
+	return c.arrayExistsToSql(field, elem, tType, sql), nil
+}
+
+// arrayContainsToSql converts equal term to the containment function of sql style,
+// it returns false if sql style doesn't have such function.
+func (c *SqlConvertor) arrayContainsToSql(
+	field string, tType *esMapping.Property, value *term.Term,
+) (string, bool, error) {
+	switch c.sqlStyle {
+	case ClickHouse, PostgreSQL, MySQL:
+	default:
+		return "", false, nil
+	}
+
+	val := value.String()
+	if value.GetTermType()&term.PHRASE_TERM_TYPE == term.PHRASE_TERM_TYPE {
+		if esMapping.CheckNumberType(tType.Type) {
+			return "", false, &UnsupportedTermError{Kind: "phrase", Type: tType.Type}
+		}
+		val = strings.Trim(val, "\"")
+	}
+	lit, err := literalToSql(tType, val)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch c.sqlStyle {
+	case ClickHouse:
+		return fmt.Sprintf("has(%s, %s)", field, lit), true, nil
+	case PostgreSQL:
+		return fmt.Sprintf("%s = ANY(%s)", lit, field), true, nil
+	default:
+		// array is stored as json array in mysql
+		if esMapping.CheckNumberType(tType.Type) {
+			// number literal is validated, it's quoted as json document
+			return fmt.Sprintf("JSON_CONTAINS(%s, %s)", field, quoteString(lit)), true, nil
+		}
+		return fmt.Sprintf("JSON_CONTAINS(%s, JSON_QUOTE(%s))", field, lit), true, nil
+	}
+}
+
+// arrayElement returns the name which refers to element of array in sub query / lambda.
+func (c *SqlConvertor) arrayElement() string {
+	if c.sqlStyle == SQLite {
+		// column name of json_each table
+		return "value"
+	}
+	return "x"
+}
+
+// arrayExistsToSql wraps predicate of element as predicate of array.
+func (c *SqlConvertor) arrayExistsToSql(
+	field, elem string, tType *esMapping.Property, sql string,
+) string {
+	switch c.sqlStyle {
+	case ClickHouse:
+		return fmt.Sprintf("arrayExists(%s -> %s, %s)", elem, sql, field)
+	case SQLite:
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) WHERE %s)", field, sql)
+	case MySQL:
+		elemType := "VARCHAR(255)"
+		if esMapping.CheckNumberType(tType.Type) {
+			elemType = "DOUBLE"
+		}
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM JSON_TABLE(%s, '$[*]' COLUMNS (%s %s PATH '$')) AS t WHERE %s)",
+			field, elem, elemType, sql)
+	case Oracle:
+		elemType := "VARCHAR2(4000)"
+		if esMapping.CheckNumberType(tType.Type) {
+			elemType = "NUMBER"
+		}
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM JSON_TABLE(%s, '$[*]' COLUMNS (%s %s PATH '$')) t WHERE %s)",
+			field, elem, elemType, sql)
+	case SQLServer:
+		// array is stored as json array in SQL Server
+		elemType := "NVARCHAR(4000)"
+		if esMapping.CheckNumberType(tType.Type) {
+			elemType = "FLOAT"
+		}
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM OPENJSON(%s) WITH (%s %s '$') AS t WHERE %s)",
+			field, elem, elemType, sql)
+	default:
+		// sql99 and postgresql
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM UNNEST(%s) AS t(%s) WHERE %s)", field, elem, sql)
+	}
+}
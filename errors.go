@@ -0,0 +1,162 @@
+package lucene_to_sql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alecthomas/participle"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+// Clause locates the clause of query which causes error, it's embedded in errors of conversion,
+// so that error can be shown with its position in query, e.g. UnknownFieldError.Offset.
+type Clause struct {
+	// Field is field of clause, it's the matched field if term is on default fields or field pattern.
+	Field string
+	// Term is term of clause.
+	Term string
+	// Offset is byte offset of clause in original query, it's -1 if clause can't be located.
+	Offset int
+}
+
+func (c *Clause) clause() *Clause {
+	return c
+}
+
+// clauseError is implemented by errors which embed Clause.
+type clauseError interface {
+	error
+	clause() *Clause
+}
+
+// ParseError is returned if query can't be parsed.
+type ParseError struct {
+	Query string
+	// Offset is byte offset of error in query, it's -1 if position is unknown.
+	Offset int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse query at offset %d, err: %v", e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownFieldError is returned if field of clause isn't in schema, or no field is matched with field pattern.
+type UnknownFieldError struct {
+	Clause
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("field: %s isn't in schema", e.Field)
+}
+
+// UnsupportedTermError is returned if kind of term (e.g. regexp) can't be queried on type of field.
+type UnsupportedTermError struct {
+	Clause
+	Kind string
+	Type esMapping.FieldType
+}
+
+func (e *UnsupportedTermError) Error() string {
+	return fmt.Sprintf("%s query: %s isn't supported on field: %s of type: %s", e.Kind, e.Term, e.Field, e.Type)
+}
+
+// TypeMismatchError is returned if value of term can't be converted to type of field, e.g. age:abc.
+type TypeMismatchError struct {
+	Clause
+	// Value is the value which can't be converted, e.g. bound of range term.
+	Value string
+	Type  esMapping.FieldType
+	Err   error
+}
+
+func (e *TypeMismatchError) Error() string {
+	msg := fmt.Sprintf("value: %s doesn't match type: %s of field: %s", e.Value, e.Type, e.Field)
+	if e.Err != nil {
+		msg += fmt.Sprintf(", err: %v", e.Err)
+	}
+	return msg
+}
+
+func (e *TypeMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedDialectFeatureError is returned if sql style can't express the query, e.g. fuzzy query of MySQL.
+type UnsupportedDialectFeatureError struct {
+	Clause
+	Dialect SQL_STYLE
+	Feature string
+}
+
+func (e *UnsupportedDialectFeatureError) Error() string {
+	if e.Field == "" && e.Term == "" {
+		// feature of statement rather than clause, e.g. LIMIT BY
+		return fmt.Sprintf("%s doesn't support %s", e.Dialect, e.Feature)
+	}
+	return fmt.Sprintf("%s doesn't support %s, clause: %s:%s", e.Dialect, e.Feature, e.Field, e.Term)
+}
+
+// RegexpSyntaxError is returned if regexp term can't be parsed or has syntax which can't be translated, e.g. /a&b/.
+type RegexpSyntaxError struct {
+	Clause
+	Pattern string
+	// Position is position of error in pattern, which is counted in chars.
+	Position int
+	Msg      string
+}
+
+func (e *RegexpSyntaxError) Error() string {
+	return fmt.Sprintf("invalid regexp: /%s/, %s at position %d", e.Pattern, e.Msg, e.Position)
+}
+
+// setClause fills field and term of clause error, which are unknown where error occurs.
+func setClause(err error, field, term string) {
+	var cErr clauseError
+	if errors.As(err, &cErr) {
+		cl := cErr.clause()
+		if cl.Field == "" {
+			cl.Field = field
+		}
+		if cl.Term == "" {
+			cl.Term = term
+		}
+	}
+}
+
+// locateError sets offset of clause error in original query.
+func locateError(err error, locator *clauseLocator) {
+	var cErr clauseError
+	if errors.As(err, &cErr) {
+		cl := cErr.clause()
+		cl.Offset = locator.locate(cl.Field, cl.Term).start
+	}
+}
+
+// newParseError converts error of lucene parser, which parses the rewritten query, to ParseError.
+// syntax error found by rewriter is preferred, because its position is in original query.
+func newParseError(query, rewritten string, operator OPERATOR, err error) *ParseError {
+	if cErr := checkQuery(query, operator); cErr != nil {
+		err, rewritten = cErr, query
+	}
+	pErr := &ParseError{Query: query, Offset: -1, Err: err}
+	var sErr *syntaxError
+	var tErr participle.Error
+	if errors.As(err, &sErr) {
+		pErr.Offset = sErr.offset
+	} else if errors.As(err, &tErr) {
+		tk := tErr.Token()
+		switch offset := tk.Pos.Offset; {
+		case offset <= len(query) && rewritten[:offset] == query[:offset]:
+			// position is kept if query isn't changed before it
+			pErr.Offset = offset
+		case tk.EOF():
+			pErr.Offset = len(query)
+		}
+	}
+	return pErr
+}
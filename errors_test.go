@@ -0,0 +1,145 @@
+package lucene_to_sql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestErrors(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"name": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+			"age": {
+				Type: esMapping.INTEGER_FIELD_TYPE,
+			},
+			"title": {
+				Type: esMapping.TEXT_FIELD_TYPE,
+			},
+		},
+	}))
+	tests := []struct {
+		name    string
+		opts    []func(*SqlConvertor)
+		query   string
+		wantErr error
+	}{
+		{
+			name:    "test unknown field",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "name:foo AND city:x",
+			wantErr: &UnknownFieldError{Clause: Clause{Field: "city", Term: "x", Offset: 13}},
+		},
+		{
+			name:    "test unknown exists field",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "name:foo AND NOT _exists_:city",
+			wantErr: &UnknownFieldError{Clause: Clause{Field: "city", Term: "city", Offset: 17}},
+		},
+		{
+			name:    "test unmatched field pattern",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "name:foo OR user.\\*:x",
+			wantErr: &UnknownFieldError{Clause: Clause{Field: "user.*", Term: "x", Offset: 12}},
+		},
+		{
+			name:    "test unsupported term",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "name:foo OR age:/1.*/",
+			wantErr: &UnsupportedTermError{Clause: Clause{Field: "age", Term: "/1.*/", Offset: 12}, Kind: "regexp", Type: esMapping.INTEGER_FIELD_TYPE},
+		},
+		{
+			name:    "test type mismatch",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "name:foo AND (age:1 OR age:abc)",
+			wantErr: &TypeMismatchError{Clause: Clause{Field: "age", Term: "abc", Offset: 23}, Value: "abc", Type: esMapping.INTEGER_FIELD_TYPE},
+		},
+		{
+			name:    "test type mismatch in term group",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "age:(1 OR abc)",
+			wantErr: &TypeMismatchError{Clause: Clause{Field: "age", Term: "abc", Offset: 10}, Value: "abc", Type: esMapping.INTEGER_FIELD_TYPE},
+		},
+		{
+			name:    "test type mismatch on default field",
+			opts:    []func(*SqlConvertor){schema, WithDefaultFields("age")},
+			query:   "1 AND abc",
+			wantErr: &TypeMismatchError{Clause: Clause{Field: "age", Term: "abc", Offset: 6}, Value: "abc", Type: esMapping.INTEGER_FIELD_TYPE},
+		},
+		{
+			name:    "test unsupported dialect feature",
+			opts:    []func(*SqlConvertor){schema, WithSQLStyle(MySQL)},
+			query:   "age:1 AND name:foo~1",
+			wantErr: &UnsupportedDialectFeatureError{Clause: Clause{Field: "name", Term: "foo~1", Offset: 10}, Dialect: MySQL, Feature: "fuzzy query without levenshtein function"},
+		},
+		{
+			name:    "test regexp syntax error",
+			opts:    []func(*SqlConvertor){schema, WithSQLStyle(MySQL)},
+			query:   "age:1 OR (name:foo AND name:/a(b/)",
+			wantErr: &RegexpSyntaxError{Clause: Clause{Field: "name", Term: "/a(b/", Offset: 23}, Pattern: "a(b", Position: 3, Msg: "expected ')'"},
+		},
+		{
+			name:    "test regexp syntax error of guardrail",
+			opts:    []func(*SqlConvertor){schema, WithSQLStyle(MySQL), WithMaxDeterminizedStates(100)},
+			query:   "name:/a&b/",
+			wantErr: &RegexpSyntaxError{Clause: Clause{Field: "name", Term: "/a&b/", Offset: 0}, Pattern: "a&b", Position: 1, Msg: "operator '&' is not supported"},
+		},
+		{
+			name:    "test denied field",
+			opts:    []func(*SqlConvertor){schema, WithFieldPolicy(&FieldPolicy{Deny: []string{"age"}})},
+			query:   "name:foo AND age:1",
+			wantErr: &FieldDeniedError{Clause: Clause{Field: "age", Term: "1", Offset: 13}},
+		},
+		{
+			name:    "test expensive query",
+			opts:    []func(*SqlConvertor){schema, WithAllowLeadingWildcard(false)},
+			query:   "age:1 OR name:*foo",
+			wantErr: &ExpensiveQueryError{Clause: Clause{Field: "name", Term: "*foo", Offset: 9}, Reason: "leading wildcard is not allowed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSqlConvertor(tt.opts...).LuceneToSql(tt.query)
+			// the typed error is returned as it is, rather than wrapped by enclosing clauses
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		offset int
+	}{
+		{
+			name:   "test unclosed paren",
+			query:  "a:(b OR c",
+			offset: 9,
+		},
+		{
+			name:   "test unexpected paren",
+			query:  "a:b)",
+			offset: 3,
+		},
+		{
+			name:   "test unclosed quote of rewritten query",
+			query:  "foo a:\"b",
+			offset: 8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSqlConvertor().LuceneToSql(tt.query)
+			var pErr *ParseError
+			if assert.True(t, errors.As(err, &pErr), err) {
+				assert.Equal(t, tt.query, pErr.Query)
+				assert.Equal(t, tt.offset, pErr.Offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+	"github.com/zhuliquan/lucene_parser/term"
+)
+
+// EXPLAIN_KIND is the kind of node of explanation.
+type EXPLAIN_KIND int32
+
+const (
+	ExplainOr    EXPLAIN_KIND = iota // clauses joined by OR
+	ExplainAnd                       // clauses joined by AND
+	ExplainGroup                     // sub query surrounded with paren
+	ExplainTerm                      // term on field, e.g. field:value / field:(a OR b)
+)
+
+func (k EXPLAIN_KIND) String() string {
+	switch k {
+	case ExplainAnd:
+		return "AND"
+	case ExplainGroup:
+		return "GROUP"
+	case ExplainTerm:
+		return "TERM"
+	default:
+		return "OR"
+	}
+}
+
+func (k EXPLAIN_KIND) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// ExplainNode tells how clause of query is converted to sql fragment, it's built by the walk of conversion.
+type ExplainNode struct {
+	Kind EXPLAIN_KIND `json:"kind"`
+	// Query is lucene clause of term, e.g. field:value
+	Query string `json:"query,omitempty"`
+	// Offset / End are byte offsets of the beginning and end of clause in original query, they're -1
+	// if clause can't be located, e.g. query[Offset:End] is status:(a OR b) / (a AND b).
+	Offset  int  `json:"offset"`
+	End     int  `json:"end"`
+	Negated bool `json:"negated,omitempty"`
+	// Dropped is true if should clause is dropped from predicate by must clauses, it only affects score.
+	Dropped bool `json:"dropped,omitempty"`
+	// Field / Column / Type are resolved field of term, its column expression and mapping type.
+	Field  string `json:"field,omitempty"`
+	Column string `json:"column,omitempty"`
+	Type   string `json:"type,omitempty"`
+	// Translator is the way term is converted, e.g. range / in_list / fuzzy.
+	Translator string         `json:"translator,omitempty"`
+	SQL        string         `json:"sql"`
+	Children   []*ExplainNode `json:"children,omitempty"`
+
+	// field and term of clause to locate it in query
+	clauseField string
+	clauseTerm  string
+}
+
+// String renders explanation as indented tree.
+func (n *ExplainNode) String() string {
+	var b strings.Builder
+	n.render(&b, 0)
+	return b.String()
+}
+
+func (n *ExplainNode) render(b *strings.Builder, indent int) {
+	b.WriteString(strings.Repeat("  ", indent))
+	if n.Dropped {
+		b.WriteString("DROPPED ")
+	}
+	if n.Negated {
+		b.WriteString("NOT ")
+	}
+	b.WriteString(n.Kind.String())
+	if n.Query != "" {
+		b.WriteString(" " + n.Query)
+	}
+	var attrs []string
+	if n.Offset != -1 {
+		attrs = append(attrs, fmt.Sprintf("offset: %d, end: %d", n.Offset, n.End))
+	}
+	if n.Field != "" {
+		attrs = append(attrs, "field: "+n.Field)
+	}
+	if n.Column != "" && n.Column != n.Field {
+		attrs = append(attrs, "column: "+n.Column)
+	}
+	if n.Type != "" {
+		attrs = append(attrs, "type: "+n.Type)
+	}
+	if n.Translator != "" {
+		attrs = append(attrs, "translator: "+n.Translator)
+	}
+	if len(attrs) != 0 {
+		fmt.Fprintf(b, " [%s]", strings.Join(attrs, ", "))
+	}
+	if n.SQL != "" {
+		// clause may be dropped, e.g. unknown field is ignored
+		b.WriteString(" => " + n.SQL)
+	}
+	b.WriteString("\n")
+	for _, child := range n.Children {
+		child.render(b, indent+1)
+	}
+}
+
+// Explain converts lucene query like Convert, and returns the tree which maps clauses of query to sql fragments.
+func (c *SqlConvertor) Explain(
+	ctx context.Context, query string, options ...func(s *SqlConvertor),
+) (*ExplainNode, error) {
+	cvt := c.clone()
+	for _, opt := range options {
+		opt(cvt)
+	}
+	cvt.explain = true
+	if _, err := cvt.convert(ctx, query, false); err != nil {
+		return nil, err
+	}
+	root := simplifyExplain(cvt.explainRoot)
+	locateExplain(root, cvt.locator)
+	return root, nil
+}
+
+// explainNode appends node to the node of enclosing clause, and returns function which sets sql of node
+// when clause is converted, e.g. defer c.explainNode(node)(&sql)
+func (c *SqlConvertor) explainNode(node *ExplainNode) func(sql *string) {
+	if !c.explain {
+		return func(*string) {}
+	}
+	if n := len(c.explainStack); n == 0 {
+		c.explainRoot = node
+	} else {
+		parent := c.explainStack[n-1]
+		parent.Children = append(parent.Children, node)
+		if parent.Kind == ExplainTerm && node.Kind == ExplainTerm {
+			// term on one of default fields / fields matched with pattern
+			node.clauseField, node.clauseTerm = parent.clauseField, parent.clauseTerm
+		}
+	}
+	c.explainStack = append(c.explainStack, node)
+	return func(sql *string) {
+		node.SQL = strings.TrimSpace(*sql)
+		c.explainStack = c.explainStack[:len(c.explainStack)-1]
+	}
+}
+
+// explainTerm sets resolved field and translator of current term node.
+func (c *SqlConvertor) explainTerm(field, column string, tType *esMapping.Property, translator string) {
+	if !c.explain || len(c.explainStack) == 0 {
+		return
+	}
+	node := c.explainStack[len(c.explainStack)-1]
+	node.Field, node.Column, node.Translator = field, column, translator
+	if tType != nil {
+		node.Type = string(tType.Type)
+	}
+}
+
+// translatorOf returns the way to convert term on field, it's same as valueQueryToSql.
+func (c *SqlConvertor) translatorOf(field string, tType *esMapping.Property, value *term.Term) string {
+	var translator string
+	switch termType := getTermType(value); {
+	case termType&term.REGEXP_TERM_TYPE == term.REGEXP_TERM_TYPE:
+		translator = "regexp"
+	case termType&term.RANGE_TERM_TYPE == term.RANGE_TERM_TYPE:
+		translator = "range"
+	case termType&term.WILDCARD_TERM_TYPE == term.WILDCARD_TERM_TYPE:
+		translator = "wildcard"
+		if c.prefixAsRange && value.FuzzyTerm.SingleTerm != nil {
+			tks := append([]string{value.FuzzyTerm.SingleTerm.Begin}, value.FuzzyTerm.SingleTerm.Chars...)
+			if _, ok := wildcardPrefix(tks); ok {
+				translator = "prefix"
+			}
+		}
+	case termType&term.FUZZY_TERM_TYPE == term.FUZZY_TERM_TYPE:
+		if value.FuzzyTerm.PhraseTerm != nil {
+			translator = "proximity"
+		} else {
+			translator = "fuzzy"
+		}
+	case termType&term.SINGLE_TERM_TYPE == term.SINGLE_TERM_TYPE,
+		termType&term.PHRASE_TERM_TYPE == term.PHRASE_TERM_TYPE:
+		translator = "term"
+		if termType&term.PHRASE_TERM_TYPE == term.PHRASE_TERM_TYPE {
+			translator = "phrase"
+		}
+		if esMapping.CheckTextType(tType.Type) {
+			translator = "like"
+		}
+	}
+	if c.arrayFields[field] {
+		translator = "array_" + translator
+	}
+	return translator
+}
+
+// simplifyExplain replaces OR / AND node which has only one child with the child.
+func simplifyExplain(node *ExplainNode) *ExplainNode {
+	for i, child := range node.Children {
+		node.Children[i] = simplifyExplain(child)
+	}
+	if (node.Kind == ExplainOr || node.Kind == ExplainAnd) && len(node.Children) == 1 {
+		return node.Children[0]
+	}
+	return node
+}
+
+// locateExplain sets spans of clauses in original query, span of group is its parens,
+// and span of node without clause covers its children.
+func locateExplain(node *ExplainNode, locator *clauseLocator) {
+	if node.Dropped {
+		// span of dropped clause is set when it's converted
+		for _, child := range node.Children {
+			locateExplain(child, locator)
+		}
+		return
+	}
+	span := noSpan
+	if node.Kind == ExplainTerm {
+		span = locator.locate(node.clauseField, node.clauseTerm)
+	}
+	for _, child := range node.Children {
+		locateExplain(child, locator)
+	}
+	if span == noSpan {
+		for _, child := range node.Children {
+			if child.Offset != -1 && (span.start == -1 || child.Offset < span.start) {
+				span.start = child.Offset
+			}
+			if child.End > span.end {
+				span.end = child.End
+			}
+		}
+		if parent, ok := locator.parent(span.start); ok && node.Kind == ExplainGroup {
+			span = parent
+		}
+	}
+	node.Offset, node.End = span.start, span.end
+}
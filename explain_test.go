@@ -0,0 +1,143 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+	"github.com/zhuliquan/lucene_parser"
+)
+
+func TestExplain(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"title": {
+					Type: esMapping.TEXT_FIELD_TYPE,
+				},
+				"status": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+				"age": {
+					Type: esMapping.INTEGER_FIELD_TYPE,
+				},
+			},
+		})),
+		WithDefaultFields("title", "status"),
+		WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldIgnore}),
+	)
+	node, err := cvt.Explain(context.Background(), "(status:(a OR b) AND NOT (title:foo OR age:[1 TO 5])) OR bar OR city:x")
+	assert.NoError(t, err)
+	assert.Equal(t, `OR [offset: 0, end: 70] => ( status IN ('a', 'b') AND  NOT ( title like '%foo%' OR age >= 1 AND age <= 5 ) ) OR ( status = 'bar' OR title like '%bar%' ) OR 1 = 0
+  GROUP [offset: 0, end: 53] => ( status IN ('a', 'b') AND  NOT ( title like '%foo%' OR age >= 1 AND age <= 5 ) )
+    AND [offset: 1, end: 52] => status IN ('a', 'b') AND  NOT ( title like '%foo%' OR age >= 1 AND age <= 5 )
+      TERM status:( a OR b ) [offset: 1, end: 16, field: status, translator: in_list] => status IN ('a', 'b')
+      NOT GROUP [offset: 25, end: 52] => NOT ( title like '%foo%' OR age >= 1 AND age <= 5 )
+        OR [offset: 26, end: 51] => title like '%foo%' OR age >= 1 AND age <= 5
+          TERM title:foo [offset: 26, end: 35, field: title, type: text, translator: like] => title like '%foo%'
+          TERM age:[ 1 TO 5 ] [offset: 39, end: 51, field: age, type: integer, translator: range] => age >= 1 AND age <= 5
+  TERM bar [offset: 57, end: 60, translator: multi_field] => ( status = 'bar' OR title like '%bar%' )
+    TERM status:bar [offset: 57, end: 60, field: status, type: keyword, translator: term] => status = 'bar'
+    TERM title:bar [offset: 57, end: 60, field: title, type: text, translator: like] => title like '%bar%'
+  TERM city:x [offset: 64, end: 70, field: city, translator: ignored] => 1 = 0
+`, node.String())
+
+	node, err = cvt.Explain(context.Background(), "age:[1 TO 5] AND NOT status:a")
+	assert.NoError(t, err)
+	data, err := json.Marshal(node)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"kind": "AND",
+		"offset": 0,
+		"end": 29,
+		"sql": "age >= 1 AND age <= 5 AND NOT ( status = 'a' )",
+		"children": [
+			{
+				"kind": "TERM",
+				"query": "age:[ 1 TO 5 ]",
+				"offset": 0,
+				"end": 12,
+				"field": "age",
+				"column": "age",
+				"type": "integer",
+				"translator": "range",
+				"sql": "age >= 1 AND age <= 5"
+			},
+			{
+				"kind": "TERM",
+				"query": "status:a",
+				"offset": 21,
+				"end": 29,
+				"negated": true,
+				"field": "status",
+				"column": "status",
+				"type": "keyword",
+				"translator": "term",
+				"sql": "NOT ( status = 'a' )"
+			}
+		]
+	}`, string(data))
+}
+
+func TestExplainDroppedShoulds(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"title": {
+					Type: esMapping.TEXT_FIELD_TYPE,
+				},
+				"name": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+			},
+		})),
+	)
+	node, err := cvt.Explain(context.Background(), "name:a AND (name:b OR name:c) title:z (name:d name:e)^2")
+	assert.NoError(t, err)
+	assert.Equal(t, `AND [offset: 0, end: 55] => name = 'a' AND ( name = 'b' OR name = 'c' )
+  AND [offset: 0, end: 29] => name = 'a' AND ( name = 'b' OR name = 'c' )
+    TERM name:a [offset: 0, end: 6, field: name, type: keyword, translator: term] => name = 'a'
+    GROUP [offset: 11, end: 29] => ( name = 'b' OR name = 'c' )
+      OR [offset: 12, end: 28] => name = 'b' OR name = 'c'
+        TERM name:b [offset: 12, end: 18, field: name, type: keyword, translator: term] => name = 'b'
+        TERM name:c [offset: 22, end: 28, field: name, type: keyword, translator: term] => name = 'c'
+  DROPPED TERM title:z [offset: 30, end: 37, field: title, type: text, translator: like] => title like '%z%'
+  DROPPED GROUP (name:d name:e)^2 [offset: 38, end: 55]
+`, node.String())
+}
+
+func TestExplainError(t *testing.T) {
+	_, err := NewSqlConvertor().Explain(context.Background(), "a:1")
+	var unknown *UnknownFieldError
+	assert.ErrorAs(t, err, &unknown)
+}
+
+func TestTranslatorOf(t *testing.T) {
+	text := &esMapping.Property{Type: esMapping.TEXT_FIELD_TYPE}
+	keyword := &esMapping.Property{Type: esMapping.KEYWORD_FIELD_TYPE}
+	tests := []struct {
+		name  string
+		opts  []func(*SqlConvertor)
+		tType *esMapping.Property
+		query string
+		want  string
+	}{
+		{name: "test regexp", tType: keyword, query: "f:/a.*/", want: "regexp"},
+		{name: "test wildcard", tType: keyword, query: "f:a*b", want: "wildcard"},
+		{name: "test prefix", opts: []func(*SqlConvertor){WithPrefixAsRange(true)}, tType: keyword, query: "f:ab*", want: "prefix"},
+		{name: "test fuzzy", tType: keyword, query: "f:ab~1", want: "fuzzy"},
+		{name: "test proximity", tType: text, query: "f:\"a b\"~2", want: "proximity"},
+		{name: "test phrase", tType: keyword, query: "f:\"a b\"", want: "phrase"},
+		{name: "test array", opts: []func(*SqlConvertor){WithArrayField("f")}, tType: keyword, query: "f:a", want: "array_term"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lucene, err := lucene_parser.ParseLucene(tt.query)
+			assert.NoError(t, err)
+			value := lucene.OrQuery.AndQuery.FieldQuery.Term
+			assert.Equal(t, tt.want, NewSqlConvertor(tt.opts...).translatorOf("f", tt.tType, value))
+		})
+	}
+}
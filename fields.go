@@ -0,0 +1,65 @@
+package lucene_to_sql
+
+import (
+	"sort"
+	"strings"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+// allFields is the pattern matches all fields in schema
+const allFields = "*"
+
+// maxFieldDepth is max number of levels of object field which are walked in schema
+const maxFieldDepth = 16
+
+// schemaFields returns all leaf fields in schema (includes sub fields of object and multi fields).
+func (c *SqlConvertor) schemaFields() map[string]*esMapping.Property {
+	var res = map[string]*esMapping.Property{}
+	if c.mappings == nil {
+		return res
+	}
+	for depth := 1; depth <= maxFieldDepth; depth++ {
+		pattern := strings.Repeat(allFields+".", depth-1) + allFields
+		props, err := c.mappings.GetProperty(pattern)
+		if err != nil {
+			continue
+		}
+		for name, property := range props {
+			res[name] = property
+		}
+	}
+	return res
+}
+
+// uniqueFields sorts fields and removes duplicated fields.
+func uniqueFields(fields []string) []string {
+	sort.Strings(fields)
+	var res []string
+	for i, field := range fields {
+		if i == 0 || field != fields[i-1] {
+			res = append(res, field)
+		}
+	}
+	return res
+}
+
+// fieldPattern returns the wildcard pattern of field, wildcard in field is escaped by rewriter (e.g. http.\*)
+// because lucene parser doesn't accept wildcard in field. it returns false if field isn't pattern.
+func fieldPattern(field string) (string, bool) {
+	if !strings.Contains(field, "\\*") && !strings.Contains(field, "\\?") {
+		return "", false
+	}
+	return strings.NewReplacer("\\*", "*", "\\?", "?").Replace(field), true
+}
+
+// matchFields returns fields in schema which match the wildcard pattern.
+func (c *SqlConvertor) matchFields(pattern string) []string {
+	var fields []string
+	for name := range c.schemaFields() {
+		if esMapping.WildcardMatch([]rune(name), []rune(pattern)) {
+			fields = append(fields, name)
+		}
+	}
+	return uniqueFields(fields)
+}
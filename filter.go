@@ -0,0 +1,154 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vjeantet/jodaTime"
+)
+
+// Filter is mandatory predicate which is ANDed with every query, e.g. tenant_id = ?
+type Filter struct {
+	// Predicate is sql predicate, ? is the placeholder of Args
+	Predicate string
+	Args      []interface{}
+}
+
+// FilterFunc computes filters of a call from context, e.g. tenant of request.
+type FilterFunc func(ctx context.Context) ([]*Filter, error)
+
+// placeholder returns the i-th (starts from 1) placeholder of parameter in sql style.
+func (c *SqlConvertor) placeholder(i int) string {
+	switch c.sqlStyle {
+	case PostgreSQL:
+		return "$" + strconv.Itoa(i)
+	case Oracle:
+		return ":" + strconv.Itoa(i)
+	case SQLServer:
+		return "@p" + strconv.Itoa(i)
+	default:
+		return "?"
+	}
+}
+
+// filtersToSql ANDs query with filters, placeholders of filters are renumbered in sql style,
+// args are inlined as literals if inline is true.
+func (c *SqlConvertor) filtersToSql(
+	ctx context.Context, query string, inline bool,
+) (string, []interface{}, error) {
+	filters := c.filters
+	for _, filterFunc := range c.filterFuncs {
+		fs, err := filterFunc(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get filters, err: %w", err)
+		}
+		filters = append(filters, fs...)
+	}
+	if len(filters) == 0 {
+		return query, nil, nil
+	}
+
+	var args []interface{}
+	sql := NewSQL()
+	sql.AddAndClause(fmt.Sprintf("( %s )", query), false, false)
+	for _, filter := range filters {
+		var predicate strings.Builder
+		var n int
+		runes := []rune(filter.Predicate)
+		for i := 0; i < len(runes); i++ {
+			if end := skipQuoted(runes, i); end != i {
+				predicate.WriteString(string(runes[i:end]))
+				i = end - 1
+				continue
+			}
+			if runes[i] == '?' {
+				if n >= len(filter.Args) {
+					return "", nil, fmt.Errorf("filter: %s has more placeholders than args", filter.Predicate)
+				}
+				if inline {
+					lit, err := c.argToSql(filter.Args[n])
+					if err != nil {
+						return "", nil, fmt.Errorf("failed to inline arg of filter: %s, err: %w", filter.Predicate, err)
+					}
+					predicate.WriteString(lit)
+				} else {
+					args = append(args, filter.Args[n])
+					predicate.WriteString(c.placeholder(len(args)))
+				}
+				n++
+				continue
+			}
+			predicate.WriteRune(runes[i])
+		}
+		if n != len(filter.Args) {
+			return "", nil, fmt.Errorf("filter: %s has %d placeholders but %d args",
+				filter.Predicate, n, len(filter.Args))
+		}
+		sql.AddAndClause(fmt.Sprintf("( %s )", predicate.String()), true, false)
+	}
+	return sql.String(), args, nil
+}
+
+// skipQuoted returns end of literal, quoted identifier or comment which starts at i, or i if there isn't one,
+// ? in them isn't placeholder, e.g. '?', "a?", `a?`, -- a? and /* a? */.
+func skipQuoted(runes []rune, i int) int {
+	switch r := runes[i]; {
+	case r == '\'' || r == '"' || r == '`':
+		// escaped quote (e.g. 'it''s') is skipped as two adjacent literals
+		for j := i + 1; j < len(runes); j++ {
+			if runes[j] == r {
+				return j + 1
+			}
+		}
+		return len(runes)
+	case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+		for j := i + 2; j < len(runes); j++ {
+			if runes[j] == '\n' {
+				return j
+			}
+		}
+		return len(runes)
+	case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+		for j := i + 2; j+1 < len(runes); j++ {
+			if runes[j] == '*' && runes[j+1] == '/' {
+				return j + 2
+			}
+		}
+		return len(runes)
+	}
+	return i
+}
+
+// argToSql converts arg of filter to sql literal, bool is 1 / 0 in SQL Server which has no boolean literal.
+func (c *SqlConvertor) argToSql(arg interface{}) (string, error) {
+	switch v := arg.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteString(v), nil
+	case bool:
+		if c.sqlStyle == SQLServer {
+			if v {
+				return "1", nil
+			}
+			return "0", nil
+		}
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return quoteString(jodaTime.Format(standardFormat, v)), nil
+	default:
+		return "", fmt.Errorf("unsupported arg type: %T", arg)
+	}
+}
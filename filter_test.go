@@ -0,0 +1,156 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+type tenantKey struct{}
+
+func TestFilters(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"a": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+			"b": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+		},
+	}))
+	tenantFilter := WithFilterFunc(func(ctx context.Context) ([]*Filter, error) {
+		tenant, ok := ctx.Value(tenantKey{}).(int)
+		if !ok {
+			return nil, errors.New("tenant is required")
+		}
+		return []*Filter{{Predicate: "tenant_id = ?", Args: []interface{}{tenant}}}, nil
+	})
+	ctx := context.WithValue(context.Background(), tenantKey{}, 42)
+	since := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		opts     []func(*SqlConvertor)
+		ctx      context.Context
+		query    string
+		wantSQL  string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "test static filter",
+			opts:     []func(*SqlConvertor){schema, WithSQLStyle(MySQL), WithFilter("tenant_id = ?", 42)},
+			ctx:      ctx,
+			query:    "a:1 OR b:2",
+			wantSQL:  "( a = '1' OR b = '2' ) AND ( tenant_id = ? )",
+			wantArgs: []interface{}{42},
+		},
+		{
+			name: "test postgresql placeholders",
+			opts: []func(*SqlConvertor){
+				schema, WithSQLStyle(PostgreSQL), tenantFilter,
+				WithFilter("ts >= ? AND status <> '?'", since),
+			},
+			ctx:      ctx,
+			query:    "a:1",
+			wantSQL:  "( a = '1' ) AND ( ts >= $1 AND status <> '?' ) AND ( tenant_id = $2 )",
+			wantArgs: []interface{}{since, 42},
+		},
+		{
+			name:     "test oracle placeholders",
+			opts:     []func(*SqlConvertor){schema, WithSQLStyle(Oracle), tenantFilter},
+			ctx:      ctx,
+			query:    "a:1",
+			wantSQL:  "( a = '1' ) AND ( tenant_id = :1 )",
+			wantArgs: []interface{}{42},
+		},
+		{
+			name: "test placeholder in quoted identifiers and comments",
+			opts: []func(*SqlConvertor){
+				schema, WithSQLStyle(PostgreSQL),
+				WithFilter("\"a?\" = ? AND b = 'it''s?' -- b?\nAND c = ? /* c? */", 1, 2),
+			},
+			ctx:      ctx,
+			query:    "a:1",
+			wantSQL:  "( a = '1' ) AND ( \"a?\" = $1 AND b = 'it''s?' -- b?\nAND c = $2 /* c? */ )",
+			wantArgs: []interface{}{1, 2},
+		},
+		{
+			name:     "test placeholder in backticks",
+			opts:     []func(*SqlConvertor){schema, WithSQLStyle(MySQL), WithFilter("`a?` = ?", 1)},
+			ctx:      ctx,
+			query:    "a:1",
+			wantSQL:  "( a = '1' ) AND ( `a?` = ? )",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name:    "test filter func error",
+			opts:    []func(*SqlConvertor){schema, tenantFilter},
+			ctx:     context.Background(),
+			query:   "a:1",
+			wantErr: true,
+		},
+		{
+			name:    "test filter args mismatch",
+			opts:    []func(*SqlConvertor){schema, WithFilter("tenant_id = ? AND region = ?", 42)},
+			ctx:     ctx,
+			query:   "a:1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := NewSqlConvertor(tt.opts...).LuceneToSqlContext(tt.ctx, tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantSQL, got)
+				assert.Equal(t, tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func TestInlineFilters(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"a": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+			},
+		})),
+		WithFilter("tenant_id = ? AND name = ?", 42, "o'k"),
+	)
+	got, err := cvt.LuceneToSql("a:1", WithFilter("ts >= ?", time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)))
+	assert.NoError(t, err)
+	assert.Equal(t, "( a = '1' ) AND ( tenant_id = 42 AND name = 'o''k' ) AND ( ts >= '2022-01-02 03:04:05' )", got)
+
+	// filter of single call isn't kept in convertor
+	got, err = cvt.LuceneToSql("a:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "( a = '1' ) AND ( tenant_id = 42 AND name = 'o''k' )", got)
+}
+
+func TestInlineBoolFilters(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"a": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+		},
+	}))
+	got, err := NewSqlConvertor(schema, WithSQLStyle(SQLServer), WithFilter("deleted = ? AND active = ?", false, true)).LuceneToSql("a:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "( a = '1' ) AND ( deleted = 0 AND active = 1 )", got)
+
+	got, err = NewSqlConvertor(schema, WithSQLStyle(PostgreSQL), WithFilter("deleted = ?", false)).LuceneToSql("a:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "( a = '1' ) AND ( deleted = FALSE )", got)
+}
@@ -0,0 +1,319 @@
+package lucene_to_sql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SqlFormat is the layout of sql, keywords are upper case, spaces are collapsed and only
+// parens which are required by precedence of operators are kept, e.g. ( a ) AND  NOT ( b ) => a AND NOT (b)
+type SqlFormat struct {
+	// MultiLine puts every clause of AND / OR on its own line, clauses in parens are indented.
+	MultiLine bool
+	// Indent is indent of clauses in parens in multi-line layout, default is two spaces.
+	Indent string
+}
+
+// keywords are upper cased by formatter, identifiers are kept as they are.
+var sqlKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "LIKE": true, "ILIKE": true, "IN": true, "IS": true, "NULL": true,
+	"BETWEEN": true, "SIMILAR": true, "TO": true, "REGEXP": true, "GLOB": true, "AGAINST": true,
+	"ESCAPE": true, "TRUE": true, "FALSE": true,
+}
+
+// sqlToken is token of sql, space is whether token is preceded by white space.
+type sqlToken struct {
+	text  string
+	space bool
+}
+
+func (t *sqlToken) is(keyword string) bool {
+	return strings.EqualFold(t.text, keyword)
+}
+
+// scanSql splits sql into words, literals, quoted identifiers, parens and the other symbols.
+func scanSql(sql string) []*sqlToken {
+	var tokens []*sqlToken
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		start, space := i, false
+		for i < len(runes) && unicode.IsSpace(runes[i]) {
+			i++
+			space = true
+		}
+		if i == len(runes) {
+			break
+		}
+		start = i
+		switch r := runes[i]; {
+		case r == '\'' || r == '"' || r == '`':
+			// quote in literal is escaped by doubling it
+			for i++; i < len(runes); i++ {
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			i++
+		case r == '(' || r == ')' || r == ',':
+			i++
+		case isWordRune(r):
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+		default:
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !isWordRune(runes[i]) &&
+				!strings.ContainsRune("'\"`(),", runes[i]) {
+				i++
+			}
+		}
+		if i > len(runes) {
+			i = len(runes)
+		}
+		tokens = append(tokens, &sqlToken{text: string(runes[start:i]), space: space})
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// operators of sql expression, which are ordered by precedence
+const (
+	sqlOr = iota
+	sqlAnd
+	sqlNot
+	sqlPredicate
+)
+
+// sqlNode is boolean expression of sql, predicate is kept as tokens.
+type sqlNode struct {
+	op       int
+	children []*sqlNode
+	tokens   []*sqlToken
+}
+
+type sqlParser struct {
+	tokens []*sqlToken
+	pos    int
+}
+
+func (p *sqlParser) peek() *sqlToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return nil
+}
+
+// parseSql parses boolean expression of sql, it returns false if sql isn't understood.
+func parseSql(sql string) (*sqlNode, bool) {
+	p := &sqlParser{tokens: scanSql(sql)}
+	node, ok := p.parseOr()
+	return node, ok && p.pos == len(p.tokens)
+}
+
+func (p *sqlParser) parseOr() (*sqlNode, bool) {
+	return p.parseBinary(sqlOr, "OR", p.parseAnd)
+}
+
+func (p *sqlParser) parseAnd() (*sqlNode, bool) {
+	return p.parseBinary(sqlAnd, "AND", p.parseNot)
+}
+
+// parseBinary parses operands joined by operator, nested operands of same operator are flattened.
+func (p *sqlParser) parseBinary(op int, keyword string, operand func() (*sqlNode, bool)) (*sqlNode, bool) {
+	node := &sqlNode{op: op}
+	for {
+		child, ok := operand()
+		if !ok {
+			return nil, false
+		}
+		if child.op == op {
+			node.children = append(node.children, child.children...)
+		} else {
+			node.children = append(node.children, child)
+		}
+		if tk := p.peek(); tk == nil || !tk.is(keyword) {
+			break
+		}
+		p.pos++
+	}
+	if len(node.children) == 1 {
+		return node.children[0], true
+	}
+	return node, true
+}
+
+func (p *sqlParser) parseNot() (*sqlNode, bool) {
+	if tk := p.peek(); tk != nil && tk.is("NOT") {
+		p.pos++
+		child, ok := p.parseNot()
+		if !ok {
+			return nil, false
+		}
+		return &sqlNode{op: sqlNot, children: []*sqlNode{child}}, true
+	}
+	return p.parsePrimary()
+}
+
+func (p *sqlParser) parsePrimary() (*sqlNode, bool) {
+	if tk := p.peek(); tk != nil && tk.text == "(" {
+		start := p.pos
+		p.pos++
+		node, ok := p.parseOr()
+		if ok && p.peek() != nil && p.peek().text == ")" {
+			p.pos++
+			if p.atBoundary() {
+				return node, true
+			}
+		}
+		// paren is part of predicate, e.g. (a + b) > 1
+		p.pos = start
+	}
+	return p.parsePredicate()
+}
+
+// atBoundary returns whether current token ends operand.
+func (p *sqlParser) atBoundary() bool {
+	tk := p.peek()
+	return tk == nil || tk.text == ")" || tk.is("AND") || tk.is("OR")
+}
+
+// parsePredicate consumes tokens until AND / OR / right paren of enclosing expression,
+// AND of BETWEEN and the tokens in parens / CASE are parts of predicate.
+func (p *sqlParser) parsePredicate() (*sqlNode, bool) {
+	node := &sqlNode{op: sqlPredicate}
+	depth, cases, between := 0, 0, false
+	for tk := p.peek(); tk != nil; tk = p.peek() {
+		if depth == 0 && cases == 0 && p.atBoundary() {
+			if !(between && tk.is("AND")) {
+				break
+			}
+			between = false
+		}
+		switch {
+		case tk.text == "(":
+			depth++
+		case tk.text == ")":
+			depth--
+		case tk.is("CASE"):
+			cases++
+		case tk.is("END") && cases > 0:
+			cases--
+		case tk.is("BETWEEN") && depth == 0 && cases == 0:
+			between = true
+		}
+		node.tokens = append(node.tokens, tk)
+		p.pos++
+	}
+	return node, len(node.tokens) != 0 && depth == 0
+}
+
+// formatSql formats sql, sql is returned as it is if it can't be parsed.
+func (f *SqlFormat) formatSql(sql string) string {
+	node, ok := parseSql(sql)
+	if !ok {
+		return sql
+	}
+	if f.MultiLine {
+		return strings.Join(f.lines(node), "\n")
+	}
+	return f.inline(node)
+}
+
+// needParens returns whether operand of operator needs parens, operand of NOT is always in parens,
+// because precedence of NOT is higher than comparison in some sql styles, e.g. HIGH_NOT_PRECEDENCE of MySQL.
+func needParens(operand *sqlNode, op int) bool {
+	return op == sqlNot || operand.op < op
+}
+
+func (f *SqlFormat) inline(node *sqlNode) string {
+	switch node.op {
+	case sqlPredicate:
+		return predicateToSql(node.tokens)
+	case sqlNot:
+		return "NOT (" + f.inline(node.children[0]) + ")"
+	}
+	operands := make([]string, 0, len(node.children))
+	for _, child := range node.children {
+		if needParens(child, node.op) {
+			operands = append(operands, "("+f.inline(child)+")")
+		} else {
+			operands = append(operands, f.inline(child))
+		}
+	}
+	if node.op == sqlOr {
+		return strings.Join(operands, " OR ")
+	}
+	return strings.Join(operands, " AND ")
+}
+
+func (f *SqlFormat) lines(node *sqlNode) []string {
+	switch node.op {
+	case sqlPredicate:
+		return []string{predicateToSql(node.tokens)}
+	case sqlNot:
+		if child := node.children[0]; child.op == sqlPredicate {
+			return []string{"NOT (" + predicateToSql(child.tokens) + ")"}
+		}
+		return f.parens("NOT ", f.lines(node.children[0]))
+	}
+	keyword := "OR "
+	if node.op == sqlAnd {
+		keyword = "AND "
+	}
+	var lines []string
+	for i, child := range node.children {
+		operand := f.lines(child)
+		if needParens(child, node.op) {
+			operand = f.parens("", operand)
+		} else if child.op < sqlNot {
+			// operand binds tighter than operator, e.g. AND in OR, its following lines are hanging
+			for j := 1; j < len(operand); j++ {
+				operand[j] = f.indent() + operand[j]
+			}
+		}
+		if i != 0 {
+			operand[0] = keyword + operand[0]
+		}
+		lines = append(lines, operand...)
+	}
+	return lines
+}
+
+// parens surrounds lines with parens and indents them.
+func (f *SqlFormat) parens(prefix string, lines []string) []string {
+	res := []string{prefix + "("}
+	for _, line := range lines {
+		res = append(res, f.indent()+line)
+	}
+	return append(res, ")")
+}
+
+func (f *SqlFormat) indent() string {
+	if f.Indent == "" {
+		return "  "
+	}
+	return f.Indent
+}
+
+// predicateToSql joins tokens of predicate with single space, keywords are upper cased.
+func predicateToSql(tokens []*sqlToken) string {
+	var b strings.Builder
+	for i, tk := range tokens {
+		if i != 0 && tk.space {
+			b.WriteString(" ")
+		}
+		// the first token is the field, e.g. column named match
+		if i != 0 && sqlKeywords[strings.ToUpper(tk.text)] {
+			b.WriteString(strings.ToUpper(tk.text))
+		} else {
+			b.WriteString(tk.text)
+		}
+	}
+	return b.String()
+}
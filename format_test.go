@@ -0,0 +1,108 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestFormatSql(t *testing.T) {
+	type args struct {
+		sql       string
+		multiLine bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "keyword_casing_and_spaces",
+			args: args{sql: "title like '%foo%' AND  NOT ( status is null )"},
+			want: "title LIKE '%foo%' AND NOT (status IS NULL)",
+		},
+		{
+			name: "keyword_in_literal",
+			args: args{sql: "title = 'a and  b' OR title = 'it''s or'"},
+			want: "title = 'a and  b' OR title = 'it''s or'",
+		},
+		{
+			name: "redundant_parens",
+			args: args{sql: "( ( a = 1 ) OR ( b = 2 AND ( c = 3 ) ) )"},
+			want: "a = 1 OR b = 2 AND c = 3",
+		},
+		{
+			name: "parens_of_precedence",
+			args: args{sql: "( a = 1 OR b = 2 ) AND ( c = 3 OR ( d = 4 OR e = 5 ) )"},
+			want: "(a = 1 OR b = 2) AND (c = 3 OR d = 4 OR e = 5)",
+		},
+		{
+			name: "between_and_case",
+			args: args{sql: "age between 1 and 5 AND CASE WHEN a = 1 AND b = 2 THEN 1 ELSE 0 END = 1"},
+			want: "age BETWEEN 1 AND 5 AND CASE WHEN a = 1 AND b = 2 THEN 1 ELSE 0 END = 1",
+		},
+		{
+			name: "parens_of_predicate",
+			args: args{sql: "MATCH (title) AGAINST ('foo' IN NATURAL LANGUAGE MODE) OR status in ('a', 'b') OR (a + b) > 1"},
+			want: "MATCH (title) AGAINST ('foo' IN NATURAL LANGUAGE MODE) OR status IN ('a', 'b') OR (a + b) > 1",
+		},
+		{
+			name: "column_named_keyword",
+			args: args{sql: "is = 'a' AND `to` = 'b'"},
+			want: "is = 'a' AND `to` = 'b'",
+		},
+		{
+			name: "unbalanced_sql_is_kept",
+			args: args{sql: "( a = 1 OR b = 2"},
+			want: "( a = 1 OR b = 2",
+		},
+		{
+			name: "multi_line",
+			args: args{sql: "a = 1 OR ( b = 2 AND ( c = 3 OR d = 4 ) ) OR NOT ( e = 5 AND f = 6 )", multiLine: true},
+			want: `a = 1
+OR b = 2
+  AND (
+    c = 3
+    OR d = 4
+  )
+OR NOT (
+  e = 5
+  AND f = 6
+)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &SqlFormat{MultiLine: tt.args.multiLine}
+			assert.Equal(t, tt.want, f.formatSql(tt.args.sql))
+		})
+	}
+}
+
+func TestWithFormat(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"title": {
+					Type: esMapping.TEXT_FIELD_TYPE,
+				},
+				"status": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+			},
+		})),
+		WithFilter("tenant = ?", 7),
+		WithFormat(&SqlFormat{}),
+	)
+	res, err := cvt.Convert(context.Background(), "(status:a OR status:b) AND NOT title:foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "(status = 'a' OR status = 'b') AND NOT (title LIKE '%foo%') AND tenant = ?", res.SQL)
+	assert.Equal(t, []interface{}{7}, res.Args)
+
+	res, err = cvt.SelectToSql(context.Background(), &Select{Table: "logs", Limit: 10}, "status:a OR title:foo",
+		WithFormat(&SqlFormat{MultiLine: true, Indent: "\t"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT *\nFROM logs\nWHERE\n\t(\n\t\tstatus = 'a'\n\t\tOR title LIKE '%foo%'\n\t)\n\tAND tenant = ?\nFETCH FIRST 10 ROWS ONLY", res.SQL)
+}
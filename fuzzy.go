@@ -0,0 +1,162 @@
+package lucene_to_sql
+
+import (
+	"fmt"
+	"strings"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+	"github.com/zhuliquan/lucene_parser/term"
+)
+
+// FUZZY_STRATEGY is the way to convert fuzzy query (e.g. foo~1) to sql.
+type FUZZY_STRATEGY int32
+
+const (
+	// EditDistance matches string whose levenshtein distance to term is within fuzziness
+	EditDistance FUZZY_STRATEGY = iota
+	// Trigram matches string which is similar to term by pg_trgm of PostgreSQL
+	Trigram
+	// Approximate matches string which has prefix of term and length near to term,
+	// it's used when sql style has no edit distance function.
+	Approximate
+)
+
+func (f FUZZY_STRATEGY) String() string {
+	switch f {
+	case Trigram:
+		return "Trigram"
+	case Approximate:
+		return "Approximate"
+	default:
+		return "EditDistance"
+	}
+}
+
+func (c *SqlConvertor) fuzzyQueryToSql(
+	field string, tType *esMapping.Property, value *term.Term,
+) (string, error) {
+	if value.FuzzyTerm.PhraseTerm != nil {
+		return c.proximityQueryToSql(field, tType, value)
+	}
+	if !esMapping.CheckStringType(tType.Type) {
+		return "", &UnsupportedTermError{Kind: "fuzzy", Type: tType.Type}
+	}
+	val := value.FuzzyTerm.SingleTerm.String()
+	var fuzziness int
+	if f := value.FuzzyTerm.Fuzzy().Float(); f == -1 {
+		fuzziness = c.defaultFuzziness(val)
+		c.warn(FuzzinessDefaulted, "fuzziness of term: %s is %d", val, fuzziness)
+	} else if fuzziness = fuzzyEdits(f, val); fuzziness > maxFuzzyEdits {
+		c.warn(FuzzinessClamped, "fuzziness: %g of term: %s is clamped to %d edits", f, val, maxFuzzyEdits)
+		fuzziness = maxFuzzyEdits
+	}
+	switch c.fuzzyStrategy {
+	case Trigram:
+		c.warn(FuzzyApproximated, "fuzzy term: %s is matched by trigram similarity", val)
+		return c.trigramToSql(field, val)
+	case Approximate:
+		c.warn(FuzzyApproximated, "fuzzy term: %s is matched by prefix and length", val)
+		return c.approximateFuzzyToSql(field, val, fuzziness), nil
+	default:
+		return c.editDistanceToSql(field, val, fuzziness)
+	}
+}
+
+// maxFuzzyEdits is max edits of fuzzy query supported by lucene
+const maxFuzzyEdits = 2
+
+// fuzzyEdits converts fuzziness to edits like FuzzyQuery.floatToEdits of lucene, fractional fuzziness is
+// min similarity of term, e.g. jon~0.5 => (1 - 0.5) * 3 = 1 edit.
+func fuzzyEdits(fuzziness float64, val string) int {
+	switch {
+	case fuzziness >= 1:
+		return int(fuzziness)
+	case fuzziness > 0:
+		return int((1 - fuzziness) * float64(len([]rune(val))))
+	default:
+		return 0
+	}
+}
+
+// defaultFuzziness returns fuzziness of ~ without number.
+func (c *SqlConvertor) defaultFuzziness(val string) int {
+	if !c.autoFuzziness {
+		return 1
+	}
+	switch n := len([]rune(val)); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistanceToSql converts fuzzy query to levenshtein distance.
+func (c *SqlConvertor) editDistanceToSql(field, val string, fuzziness int) (string, error) {
+	lit := quoteString(val)
+	if c.levenshteinFunc != "" {
+		return fmt.Sprintf("%s(%s, %s) <= %d", c.levenshteinFunc, field, lit, fuzziness), nil
+	}
+	switch c.sqlStyle {
+	case PostgreSQL:
+		// CREATE EXTENSION fuzzystrmatch;
+		return fmt.Sprintf("levenshtein(%s, %s) <= %d", field, lit, fuzziness), nil
+	case Oracle:
+		// EDIT_DISTANCE returns -1 if one of strings is null
+		return fmt.Sprintf("UTL_MATCH.EDIT_DISTANCE(%s, %s) BETWEEN 0 AND %d", field, lit, fuzziness), nil
+	case ClickHouse:
+		if c.fuzzyTranspositions {
+			return fmt.Sprintf("damerauLevenshteinDistance(%s, %s) <= %d", field, lit, fuzziness), nil
+		}
+		return fmt.Sprintf("editDistance(%s, %s) <= %d", field, lit, fuzziness), nil
+	default:
+		return "", &UnsupportedDialectFeatureError{Dialect: c.sqlStyle, Feature: "fuzzy query without levenshtein function"}
+	}
+}
+
+// trigramToSql converts fuzzy query to trigram similarity, CREATE EXTENSION pg_trgm;
+func (c *SqlConvertor) trigramToSql(field, val string) (string, error) {
+	if c.sqlStyle != PostgreSQL {
+		return "", &UnsupportedDialectFeatureError{Dialect: c.sqlStyle, Feature: "trigram fuzzy query"}
+	}
+	if c.trigramThreshold > 0 {
+		return fmt.Sprintf("similarity(%s, %s) >= %g", field, quoteString(val), c.trigramThreshold), nil
+	}
+	// threshold is pg_trgm.similarity_threshold
+	return fmt.Sprintf("%s %% %s", field, quoteString(val)), nil
+}
+
+// approximateFuzzyToSql approximates fuzzy query by prefix and length of term, for instance
+// jon~1 => field LIKE 'jo%' AND LENGTH(field) BETWEEN 2 AND 4, prefix is the term without last fuzziness chars.
+func (c *SqlConvertor) approximateFuzzyToSql(field, val string, fuzziness int) string {
+	chars := []rune(val)
+	minLen, maxLen := len(chars)-fuzziness, len(chars)+fuzziness
+	if minLen < 0 {
+		minLen = 0
+	}
+	prefix := string(chars[:minLen])
+	// prefix is cut at wildcard of like, so escape isn't needed
+	if i := strings.IndexAny(prefix, "%_\\"); i != -1 {
+		prefix = prefix[:i]
+	}
+
+	var length string
+	switch c.sqlStyle {
+	case ClickHouse:
+		length = fmt.Sprintf("lengthUTF8(%s)", field)
+	case SQLite, Oracle, PostgreSQL:
+		length = fmt.Sprintf("LENGTH(%s)", field)
+	case SQLServer:
+		length = fmt.Sprintf("LEN(%s)", field)
+	default:
+		length = fmt.Sprintf("CHAR_LENGTH(%s)", field)
+	}
+	sql := NewSQL()
+	if prefix != "" {
+		sql.AddAndClause(fmt.Sprintf("%s LIKE %s", field, quoteString(prefix+"%")), false, false)
+	}
+	sql.AddAndClause(fmt.Sprintf("%s BETWEEN %d AND %d", length, minLen, maxLen), prefix != "", false)
+	return sql.String()
+}
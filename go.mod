@@ -3,6 +3,7 @@ module github.com/zhuliquan/lucene-to-sql
 go 1.16
 
 require (
+	github.com/alecthomas/participle v0.7.1
 	github.com/stretchr/testify v1.9.0
 	github.com/vjeantet/jodaTime v1.0.0
 	github.com/zhuliquan/datemath_parser v0.0.10
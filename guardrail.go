@@ -0,0 +1,258 @@
+package lucene_to_sql
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zhuliquan/lucene_parser/term"
+)
+
+// ExpensiveQueryError is returned if clause of query is rejected by guardrails, e.g. leading wildcard.
+type ExpensiveQueryError struct {
+	Clause
+	Reason string
+}
+
+func (e *ExpensiveQueryError) Error() string {
+	return fmt.Sprintf("clause: %s:%s is rejected, %s", e.Field, e.Term, e.Reason)
+}
+
+// checkExpensiveQuery checks the term on field according to guardrails, which are same as ES:
+// allow_leading_wildcard, max_determinized_states and search.allow_expensive_queries.
+func (c *SqlConvertor) checkExpensiveQuery(field string, value *term.Term) error {
+	termType := getTermType(value)
+	reject := func(format string, args ...interface{}) error {
+		return &ExpensiveQueryError{Clause: Clause{Field: field, Term: value.String()}, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	switch {
+	case termType&term.REGEXP_TERM_TYPE == term.REGEXP_TERM_TYPE:
+		if !c.allowExpensiveQueries {
+			return reject("regexp query is not allowed")
+		}
+		if c.maxDeterminizedStates > 0 {
+			val := value.String()
+			node, err := parseRegexp(val[1 : len(val)-1])
+			if err != nil {
+				return err
+			}
+			if states := determinizedStates(node, c.maxDeterminizedStates); states > c.maxDeterminizedStates {
+				return reject("regexp is too complex, states exceed max determinized states: %d", c.maxDeterminizedStates)
+			}
+		}
+	case termType&term.WILDCARD_TERM_TYPE == term.WILDCARD_TERM_TYPE:
+		if !c.allowExpensiveQueries {
+			return reject("wildcard query is not allowed")
+		}
+		if begin := value.FuzzyTerm.SingleTerm.Begin; !c.allowLeadingWildcard && (begin == "*" || begin == "?") {
+			return reject("leading wildcard is not allowed")
+		}
+	case termType&term.FUZZY_TERM_TYPE == term.FUZZY_TERM_TYPE && value.FuzzyTerm.SingleTerm != nil:
+		if !c.allowExpensiveQueries {
+			return reject("fuzzy query is not allowed")
+		}
+	}
+	return nil
+}
+
+// maxNfaStatesPerDfaState bounds thompson nfa of regexp by times of limit of determinized states,
+// nfa which is much larger than limit is rejected without determinizing it, e.g. (a{1000}){1000}.
+const maxNfaStatesPerDfaState = 16
+
+// nfa is thompson automaton of regexp, state transits to other states by epsilon or char in ranges.
+type nfa struct {
+	eps       [][]int
+	edges     [][]nfaEdge
+	maxStates int
+	tooLarge  bool
+}
+
+type nfaEdge struct {
+	ranges [][2]rune
+	to     int
+}
+
+func (a *nfa) newState() int {
+	if len(a.eps) >= a.maxStates {
+		a.tooLarge = true
+	}
+	a.eps = append(a.eps, nil)
+	a.edges = append(a.edges, nil)
+	return len(a.eps) - 1
+}
+
+func (a *nfa) addEdge(from int, ranges [][2]rune) int {
+	to := a.newState()
+	a.edges[from] = append(a.edges[from], nfaEdge{ranges: ranges, to: to})
+	return to
+}
+
+// build adds states which match node from state, and returns the state where match ends.
+func (a *nfa) build(node regexpNode, from int) int {
+	if a.tooLarge {
+		return from
+	}
+	switch n := node.(type) {
+	case *regexpConcat:
+		for _, item := range n.items {
+			from = a.build(item, from)
+		}
+		return from
+	case *regexpUnion:
+		end := a.newState()
+		for _, alt := range n.alts {
+			start := a.newState()
+			a.eps[from] = append(a.eps[from], start)
+			last := a.build(alt, start)
+			a.eps[last] = append(a.eps[last], end)
+		}
+		return end
+	case *regexpRepeat:
+		for i := 0; i < n.min && !a.tooLarge; i++ {
+			from = a.build(n.node, from)
+		}
+		if n.max == -1 {
+			loop := a.newState()
+			a.eps[from] = append(a.eps[from], loop)
+			last := a.build(n.node, loop)
+			a.eps[last] = append(a.eps[last], loop)
+			return loop
+		}
+		end := a.newState()
+		for i := n.min; i < n.max && !a.tooLarge; i++ {
+			a.eps[from] = append(a.eps[from], end)
+			from = a.build(n.node, from)
+		}
+		a.eps[from] = append(a.eps[from], end)
+		return end
+	case *regexpChar:
+		return a.addEdge(from, [][2]rune{{n.r, n.r}})
+	case *regexpAnyChar:
+		return a.addEdge(from, [][2]rune{{0, unicode.MaxRune}})
+	case *regexpClass:
+		return a.addEdge(from, classRanges(n))
+	default:
+		return from
+	}
+}
+
+// closure returns sorted states which are reachable from states by epsilon, only the states which
+// transit by char or accept are kept, so that subsets which differ in epsilon states are same state of dfa.
+func (a *nfa) closure(states []int, accept int) []int {
+	seen := make(map[int]bool, len(states))
+	stack := append([]int(nil), states...)
+	for len(stack) != 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		stack = append(stack, a.eps[s]...)
+	}
+	res := make([]int, 0, len(seen))
+	for s := range seen {
+		if len(a.edges[s]) != 0 || s == accept {
+			res = append(res, s)
+		}
+	}
+	sort.Ints(res)
+	return res
+}
+
+// classRanges returns sorted and merged ranges of chars matched by class, negated class is complemented.
+func classRanges(n *regexpClass) [][2]rune {
+	ranges := append([][2]rune(nil), n.ranges...)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	var merged [][2]rune
+	for _, rg := range ranges {
+		if last := len(merged) - 1; last >= 0 && rg[0] <= merged[last][1]+1 {
+			if rg[1] > merged[last][1] {
+				merged[last][1] = rg[1]
+			}
+			continue
+		}
+		merged = append(merged, rg)
+	}
+	if !n.negate {
+		return merged
+	}
+	var complement [][2]rune
+	var lo rune
+	for _, rg := range merged {
+		if rg[0] > lo {
+			complement = append(complement, [2]rune{lo, rg[0] - 1})
+		}
+		lo = rg[1] + 1
+	}
+	if lo <= unicode.MaxRune {
+		complement = append(complement, [2]rune{lo, unicode.MaxRune})
+	}
+	return complement
+}
+
+// determinizedStates counts states of dfa of regexp by subset construction like lucene,
+// construction stops once count exceeds limit.
+func determinizedStates(node regexpNode, limit int) int {
+	a := &nfa{maxStates: limit * maxNfaStatesPerDfaState}
+	start := a.newState()
+	accept := a.build(node, start)
+	if a.tooLarge {
+		return limit + 1
+	}
+
+	key := func(states []int) string {
+		var b strings.Builder
+		for _, s := range states {
+			b.WriteString(strconv.Itoa(s))
+			b.WriteByte(',')
+		}
+		return b.String()
+	}
+	queue := [][]int{a.closure([]int{start}, accept)}
+	seen := map[string]bool{key(queue[0]): true}
+	for len(queue) != 0 {
+		states := queue[0]
+		queue = queue[1:]
+		// bounds of ranges split chars into segments, chars in same segment transit to same states
+		var bounds []rune
+		for _, s := range states {
+			for _, edge := range a.edges[s] {
+				for _, rg := range edge.ranges {
+					bounds = append(bounds, rg[0], rg[1]+1)
+				}
+			}
+		}
+		sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+		for i, r := range bounds {
+			if i != 0 && bounds[i-1] == r {
+				continue
+			}
+			var next []int
+			for _, s := range states {
+				for _, edge := range a.edges[s] {
+					for _, rg := range edge.ranges {
+						if rg[0] <= r && r <= rg[1] {
+							next = append(next, edge.to)
+							break
+						}
+					}
+				}
+			}
+			if len(next) == 0 {
+				continue
+			}
+			next = a.closure(next, accept)
+			if k := key(next); !seen[k] {
+				if seen[k] = true; len(seen) > limit {
+					return len(seen)
+				}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return len(seen)
+}
@@ -0,0 +1,129 @@
+package lucene_to_sql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestCheckExpensiveQuery(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"msg": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+		},
+	}))
+	tests := []struct {
+		name    string
+		opts    []func(*SqlConvertor)
+		query   string
+		wantSQL string
+		wantErr string
+	}{
+		{
+			name:    "test leading wildcard allowed by default",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema},
+			query:   "msg:*error*",
+			wantSQL: "msg LIKE '%error%'",
+		},
+		{
+			name:    "test leading wildcard",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema, WithAllowLeadingWildcard(false)},
+			query:   "msg:*error*",
+			wantErr: "clause: msg:*error* is rejected, leading wildcard is not allowed",
+		},
+		{
+			name:    "test trailing wildcard",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema, WithAllowLeadingWildcard(false)},
+			query:   "msg:error*",
+			wantSQL: "msg LIKE 'error%'",
+		},
+		{
+			name:    "test expensive wildcard",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema, WithAllowExpensiveQueries(false)},
+			query:   "msg:error*",
+			wantErr: "clause: msg:error* is rejected, wildcard query is not allowed",
+		},
+		{
+			name:    "test expensive regexp",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema, WithAllowExpensiveQueries(false)},
+			query:   "msg:/.*x.*/",
+			wantErr: "clause: msg:/.*x.*/ is rejected, regexp query is not allowed",
+		},
+		{
+			name:    "test expensive fuzzy",
+			opts:    []func(*SqlConvertor){WithSQLStyle(PostgreSQL), schema, WithAllowExpensiveQueries(false)},
+			query:   "msg:error~1",
+			wantErr: "clause: msg:error~1 is rejected, fuzzy query is not allowed",
+		},
+		{
+			name:    "test max determinized states",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema, WithMaxDeterminizedStates(100)},
+			query:   "msg:/(ab{10}){20}/",
+			wantErr: "clause: msg:/(ab{10}){20}/ is rejected, regexp is too complex, states exceed max determinized states: 100",
+		},
+		{
+			name:    "test within max determinized states",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema, WithMaxDeterminizedStates(100)},
+			query:   "msg:/(ab{10}){2}/",
+			wantSQL: "msg REGEXP '^(ab{10}){2}$'",
+		},
+		{
+			name:    "test exponential determinized states",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema, WithMaxDeterminizedStates(10000)},
+			query:   "msg:/(a|b)*a(a|b){20}/",
+			wantErr: "clause: msg:/(a|b)*a(a|b){20}/ is rejected, regexp is too complex, states exceed max determinized states: 10000",
+		},
+		{
+			name:    "test determinized states of loops",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema, WithMaxDeterminizedStates(2)},
+			query:   "msg:/.*x.*/",
+			wantSQL: "msg REGEXP '^.*x.*$'",
+		},
+		{
+			name:    "test guardrail on default fields",
+			opts:    []func(*SqlConvertor){WithSQLStyle(MySQL), schema, WithDefaultFields("msg"), WithAllowLeadingWildcard(false)},
+			query:   "*error",
+			wantErr: "clause: msg:*error is rejected, leading wildcard is not allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSqlConvertor(tt.opts...).LuceneToSql(tt.query)
+			if tt.wantErr != "" {
+				var expensiveErr *ExpensiveQueryError
+				assert.True(t, errors.As(err, &expensiveErr))
+				assert.Equal(t, tt.wantErr, expensiveErr.Error())
+				assert.Equal(t, "msg", expensiveErr.Field)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantSQL, got)
+			}
+		})
+	}
+}
+
+func TestDeterminizedStates(t *testing.T) {
+	tests := []struct {
+		pattern string
+		limit   int
+		want    int
+	}{
+		{pattern: "abc", limit: 100, want: 4},
+		{pattern: "[^a-c]x|[d-z]y", limit: 100, want: 4},
+		{pattern: ".*x.*", limit: 100, want: 2},
+		{pattern: "(a|b)*a(a|b){3}", limit: 100, want: 16},
+		{pattern: "(a|b)*a(a|b){20}", limit: 1000, want: 1001},
+		{pattern: "(a{1000}){1000}", limit: 100, want: 101},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			node, err := parseRegexp(tt.pattern)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, determinizedStates(node, tt.limit))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package lucene_to_sql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+	"github.com/zhuliquan/lucene_parser/term"
+)
+
+// oracle doesn't allow more than 1000 expressions in a list
+const oracleMaxInListSize = 1000
+
+// termGroupToInSql converts disjunction of equal terms on one field to IN list, e.g. status:(200 OR 201)
+// is converted to status IN (200, 201). it returns false if term group can't be converted to IN list.
+func (c *SqlConvertor) termGroupToInSql(
+	field string, termGroup *term.TermGroup, reverse bool,
+) (string, bool, error) {
+	if c.arrayFields[field] {
+		return "", false, nil
+	}
+	elems, ok := collectInElements(termGroup.LogicTermGroup, nil)
+	if !ok || len(elems) < 2 {
+		return "", false, nil
+	}
+	column, tType, err := c.resolveField(field)
+	if err != nil {
+		return "", false, err
+	}
+	if !esMapping.CheckNumberType(tType.Type) &&
+		!esMapping.CheckKeywordType(tType.Type) &&
+		!esMapping.CheckIPType(tType.Type) &&
+		!esMapping.CheckVersionType(tType.Type) {
+		return "", false, nil
+	}
+
+	var values []string
+	var seen = map[string]bool{}
+	for _, elem := range elems {
+		var val string
+		if elem.PhraseTerm != nil {
+			if esMapping.CheckNumberType(tType.Type) {
+				return "", false, nil
+			}
+			val = strings.Trim(elem.PhraseTerm.String(), "\"")
+		} else {
+			val = elem.SingleTerm.String()
+		}
+		lit, err := literalToSql(tType, val)
+		var mismatch *TypeMismatchError
+		if c.lenient && errors.As(err, &mismatch) {
+			// mismatched term is dropped by per term conversion
+			return "", false, nil
+		} else if err != nil {
+			setClause(err, field, val)
+			return "", false, err
+		}
+		if !seen[lit] {
+			seen[lit] = true
+			values = append(values, lit)
+		}
+	}
+
+	op, conj := "IN", "OR"
+	if reverse {
+		op, conj = "NOT IN", "AND"
+	}
+	size := c.getMaxInListSize()
+	if size <= 0 || len(values) <= size {
+		return fmt.Sprintf("%s %s (%s)", column, op, strings.Join(values, ", ")), true, nil
+	}
+	var clauses []string
+	for i := 0; i < len(values); i += size {
+		end := i + size
+		if end > len(values) {
+			end = len(values)
+		}
+		clauses = append(clauses, fmt.Sprintf("%s %s (%s)", column, op, strings.Join(values[i:end], ", ")))
+	}
+	return fmt.Sprintf("( %s )", strings.Join(clauses, " "+conj+" ")), true, nil
+}
+
+func (c *SqlConvertor) getMaxInListSize() int {
+	if c.maxInListSize != 0 {
+		return c.maxInListSize
+	}
+	if c.sqlStyle == Oracle {
+		return oracleMaxInListSize
+	}
+	return 0
+}
+
+// collectInElements collects elements of term group, which only consists of single / phrase terms joined by OR.
+func collectInElements(group *term.LogicTermGroup, elems []*term.FieldTermGroup) ([]*term.FieldTermGroup, bool) {
+	if group == nil {
+		return nil, false
+	}
+	orGroups := []*term.OrTermGroup{group.OrTermGroup}
+	for _, osGroup := range group.OSTermGroup {
+		orGroups = append(orGroups, osGroup.OrTermGroup)
+	}
+	for _, orGroup := range orGroups {
+		if orGroup == nil || orGroup.AndTermGroup == nil ||
+			len(orGroup.AnSTermGroup) != 0 || orGroup.AndTermGroup.NotSymbol != nil {
+			return nil, false
+		}
+		andGroup := orGroup.AndTermGroup
+		if andGroup.ParenTermGroup != nil {
+			var ok bool
+			if elems, ok = collectInElements(andGroup.ParenTermGroup.SubTermGroup, elems); !ok {
+				return nil, false
+			}
+			continue
+		}
+		elem := andGroup.FieldTermGroup
+		if elem == nil {
+			return nil, false
+		}
+		switch {
+		case elem.PhraseTerm != nil:
+		case elem.SingleTerm != nil && !isWildcard(elem.SingleTerm):
+		default:
+			return nil, false
+		}
+		elems = append(elems, elem)
+	}
+	return elems, true
+}
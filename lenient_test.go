@@ -0,0 +1,117 @@
+package lucene_to_sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+	"github.com/zhuliquan/lucene_parser"
+)
+
+func TestLenient(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"name": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+			"age": {
+				Type: esMapping.INTEGER_FIELD_TYPE,
+			},
+			"born": {
+				Type:   esMapping.DATE_FIELD_TYPE,
+				Format: "yyyy-MM-dd",
+			},
+		},
+	}))
+	lenient := WithLenient(true)
+	tests := []struct {
+		name     string
+		opts     []func(*SqlConvertor)
+		query    string
+		wantSQL  string
+		wantErr  bool
+		warnings int
+	}{
+		{
+			name:    "test strict number",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "age:abc",
+			wantErr: true,
+		},
+		{
+			name:    "test strict range",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "age:[abc TO 5]",
+			wantErr: true,
+		},
+		{
+			name:    "test strict date",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "born:yesterday",
+			wantErr: true,
+		},
+		{
+			name:     "test lenient number",
+			opts:     []func(*SqlConvertor){schema, lenient},
+			query:    "name:foo AND age:abc",
+			wantSQL:  "name = 'foo' AND 1 = 0",
+			warnings: 1,
+		},
+		{
+			name:     "test lenient not",
+			opts:     []func(*SqlConvertor){schema, lenient},
+			query:    "name:foo AND NOT age:abc",
+			wantSQL:  "name = 'foo' AND NOT ( 1 = 0 )",
+			warnings: 1,
+		},
+		{
+			name:     "test lenient range",
+			opts:     []func(*SqlConvertor){schema, lenient},
+			query:    "age:[abc TO 5] OR age:{1 TO *}",
+			wantSQL:  "1 = 0 OR age > 1",
+			warnings: 1,
+		},
+		{
+			name:     "test lenient date",
+			opts:     []func(*SqlConvertor){schema, lenient},
+			query:    "born:yesterday OR born:[2020-01-01 TO xyz]",
+			wantSQL:  "1 = 0 OR 1 = 0",
+			warnings: 2,
+		},
+		{
+			name:     "test lenient term group",
+			opts:     []func(*SqlConvertor){schema, lenient},
+			query:    "age:(1 OR abc)",
+			wantSQL:  "( age = 1 OR 1 = 0 )",
+			warnings: 1,
+		},
+		{
+			name:     "test lenient valid values",
+			opts:     []func(*SqlConvertor){schema, lenient},
+			query:    "age:(1 OR 2) AND born:[2020-01-01 TO 2021-01-01}",
+			wantSQL:  "age IN (1, 2) AND born >= '2020-01-01 00:00:00' AND born < '2021-01-01 00:00:00'",
+			warnings: 0,
+		},
+		{
+			name:    "test lenient keeps other errors",
+			opts:    []func(*SqlConvertor){schema, lenient},
+			query:   "unknown:abc",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cvt := NewSqlConvertor(tt.opts...)
+			lucene, err := lucene_parser.ParseLucene(parseQueryLevel(tt.query, cvt.defaultOperator).String())
+			assert.NoError(t, err)
+			got, err := cvt.luceneToSql(lucene)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantSQL, got)
+				assert.Len(t, cvt.warnings, tt.warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package lucene_to_sql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zhuliquan/lucene_parser/term"
+)
+
+var (
+	ErrMaxDepthExceeded     = errors.New("query is nested too deeply")
+	ErrTooManyClauses       = errors.New("query has too many clauses")
+	ErrTermGroupTooLarge    = errors.New("term group has too many terms")
+	ErrMaxSqlLengthExceeded = errors.New("generated sql is too long")
+)
+
+// isLimitError checks whether err is caused by limits of query complexity.
+func isLimitError(err error) bool {
+	return errors.Is(err, ErrMaxDepthExceeded) ||
+		errors.Is(err, ErrTooManyClauses) ||
+		errors.Is(err, ErrTermGroupTooLarge) ||
+		errors.Is(err, ErrMaxSqlLengthExceeded)
+}
+
+// enterParen increases depth of nested query, the caller must call leaveParen after sub query is converted.
+func (c *SqlConvertor) enterParen() error {
+	c.depth++
+	if c.maxDepth > 0 && c.depth > c.maxDepth {
+		return fmt.Errorf("%w, max depth is %d", ErrMaxDepthExceeded, c.maxDepth)
+	}
+	return nil
+}
+
+func (c *SqlConvertor) leaveParen() {
+	c.depth--
+}
+
+// addClauses counts the clauses of query, like indices.query.bool.max_clause_count of ES.
+func (c *SqlConvertor) addClauses(n int) error {
+	c.clauses += n
+	if c.maxClauseCount > 0 && c.clauses > c.maxClauseCount {
+		return fmt.Errorf("%w, max clause count is %d", ErrTooManyClauses, c.maxClauseCount)
+	}
+	return nil
+}
+
+// checkTermGroupSize checks number of terms in term group, e.g. field:(a OR b OR c)
+func (c *SqlConvertor) checkTermGroupSize(field string, termGroup *term.TermGroup) error {
+	if c.maxTermGroupSize <= 0 {
+		return nil
+	}
+	if n := countGroupTerms(termGroup.LogicTermGroup); n > c.maxTermGroupSize {
+		return fmt.Errorf("%w, term group of field: %s has %d terms, max size is %d",
+			ErrTermGroupTooLarge, field, n, c.maxTermGroupSize)
+	}
+	return nil
+}
+
+func (c *SqlConvertor) checkSqlLength(sql string) error {
+	if c.maxSqlLength > 0 && len(sql) > c.maxSqlLength {
+		return fmt.Errorf("%w, length of sql is %d, max length is %d",
+			ErrMaxSqlLengthExceeded, len(sql), c.maxSqlLength)
+	}
+	return nil
+}
+
+func countGroupTerms(group *term.LogicTermGroup) int {
+	if group == nil {
+		return 0
+	}
+	orGroups := []*term.OrTermGroup{group.OrTermGroup}
+	for _, osGroup := range group.OSTermGroup {
+		orGroups = append(orGroups, osGroup.OrTermGroup)
+	}
+	var n int
+	for _, orGroup := range orGroups {
+		if orGroup == nil {
+			continue
+		}
+		andGroups := []*term.AndTermGroup{orGroup.AndTermGroup}
+		for _, ansGroup := range orGroup.AnSTermGroup {
+			andGroups = append(andGroups, ansGroup.AndTermGroup)
+		}
+		for _, andGroup := range andGroups {
+			switch {
+			case andGroup == nil:
+			case andGroup.ParenTermGroup != nil:
+				n += countGroupTerms(andGroup.ParenTermGroup.SubTermGroup)
+			case andGroup.FieldTermGroup != nil:
+				n++
+			}
+		}
+	}
+	return n
+}
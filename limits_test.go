@@ -0,0 +1,99 @@
+package lucene_to_sql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestQueryLimits(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"a": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+			"b": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+		},
+	}))
+	tests := []struct {
+		name    string
+		opts    []func(*SqlConvertor)
+		query   string
+		wantSQL string
+		wantErr error
+	}{
+		{
+			name:    "test within max depth",
+			opts:    []func(*SqlConvertor){schema, WithMaxDepth(2)},
+			query:   "((a:1 OR b:2))",
+			wantSQL: "( ( a = '1' OR b = '2' ) )",
+		},
+		{
+			name:    "test max depth",
+			opts:    []func(*SqlConvertor){schema, WithMaxDepth(2)},
+			query:   "(((a:1 OR b:2)))",
+			wantErr: ErrMaxDepthExceeded,
+		},
+		{
+			name:    "test max depth of term group",
+			opts:    []func(*SqlConvertor){schema, WithMaxDepth(1)},
+			query:   "(a:(1 AND 2))",
+			wantErr: ErrMaxDepthExceeded,
+		},
+		{
+			name:    "test max clause count",
+			opts:    []func(*SqlConvertor){schema, WithMaxClauseCount(2)},
+			query:   "a:1 OR b:2 OR a:3",
+			wantErr: ErrTooManyClauses,
+		},
+		{
+			name:    "test max clause count of in list",
+			opts:    []func(*SqlConvertor){schema, WithMaxClauseCount(2)},
+			query:   "a:(1 OR 2 OR 3)",
+			wantErr: ErrTooManyClauses,
+		},
+		{
+			name:    "test max clause count of default fields",
+			opts:    []func(*SqlConvertor){schema, WithDefaultFields("a", "b"), WithMaxClauseCount(1)},
+			query:   "foo",
+			wantErr: ErrTooManyClauses,
+		},
+		{
+			name:    "test within max clause count",
+			opts:    []func(*SqlConvertor){schema, WithMaxClauseCount(3)},
+			query:   "a:1 OR b:(2 OR 3)",
+			wantSQL: "a = '1' OR b IN ('2', '3')",
+		},
+		{
+			name:    "test max term group size",
+			opts:    []func(*SqlConvertor){schema, WithMaxTermGroupSize(2)},
+			query:   "a:(1 OR (2 AND 3))",
+			wantErr: ErrTermGroupTooLarge,
+		},
+		{
+			name:    "test max sql length",
+			opts:    []func(*SqlConvertor){schema, WithMaxSqlLength(10)},
+			query:   "a:1 OR b:2",
+			wantErr: ErrMaxSqlLengthExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cvt := NewSqlConvertor(tt.opts...)
+			got, err := cvt.LuceneToSql(tt.query)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantSQL, got)
+			}
+			// states of conversion aren't kept in convertor
+			assert.Equal(t, 0, cvt.depth)
+			assert.Equal(t, 0, cvt.clauses)
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package lucene_to_sql
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/vjeantet/jodaTime"
@@ -20,6 +23,7 @@ const (
 	Oracle
 	PostgreSQL
 	ClickHouse
+	SQLServer
 )
 
 func (s SQL_STYLE) String() string {
@@ -34,11 +38,28 @@ func (s SQL_STYLE) String() string {
 		return "PostgreSQL"
 	case ClickHouse:
 		return "ClickHouse"
+	case SQLServer:
+		return "SQLServer"
 	default:
 		return "SQL99"
 	}
 }
 
+// OPERATOR is the default operator which joins clauses without explicit operator, e.g. foo bar
+type OPERATOR int32
+
+const (
+	OR OPERATOR = iota // lucene default
+	AND
+)
+
+func (o OPERATOR) String() string {
+	if o == AND {
+		return "AND"
+	}
+	return "OR"
+}
+
 type Tokenizer interface {
 	Split(string) []string
 }
@@ -50,6 +71,83 @@ type SqlConvertor struct {
 	mappings *esMapping.PropertyMapping
 
 	sqlStyle SQL_STYLE
+
+	// fields which are stored as array column (e.g. Array(String) / text[])
+	arrayFields map[string]bool
+
+	// field prefix => map column, e.g. labels.env is key env of map column labels
+	mapFields map[string]*MapField
+
+	// max number of elements in one IN list, zero means the default limit of sql style
+	maxInListSize int
+
+	// fields are queried by term without field, "*" means all text / keyword fields in schema
+	defaultFields []string
+
+	// operator joins clauses without explicit operator and the tokens of text
+	defaultOperator OPERATOR
+
+	// regexp query matches case insensitively
+	caseInsensitiveRegexp bool
+
+	// strategy of fuzzy query, see FUZZY_STRATEGY
+	fuzzyStrategy FUZZY_STRATEGY
+	// name of levenshtein function, e.g. user defined function of mysql / sqlite
+	levenshteinFunc string
+	// min similarity of trigram strategy, zero means similarity threshold of pg_trgm
+	trigramThreshold float64
+	// edit distance counts transposition of two adjacent chars as one edit, default is true like lucene
+	fuzzyTranspositions bool
+	// fuzziness of ~ without number depends on length of term like AUTO of ES, otherwise it's 1
+	autoFuzziness bool
+
+	// prefix query (e.g. abc*) is converted to range rather than LIKE
+	prefixAsRange bool
+
+	// guardrails of expensive query, see checkExpensiveQuery
+	allowLeadingWildcard  bool
+	allowExpensiveQueries bool
+	maxDeterminizedStates int
+
+	// decides which fields can be queried
+	fieldPolicy *FieldPolicy
+
+	// value which doesn't match type of field is converted to predicate matches nothing rather than error
+	lenient bool
+
+	// score of term on text field is rank of full text search of sql style rather than boost
+	rankFullText bool
+	// expression of relevance score is generated with predicate, see Result.Score
+	scoring bool
+
+	// layout of sql, nil means sql isn't formatted
+	format *SqlFormat
+
+	// decides how to query field which isn't in schema
+	unknownFieldPolicy *UnknownFieldPolicy
+
+	// mandatory predicates which are ANDed with query
+	filters     []*Filter
+	filterFuncs []FilterFunc
+
+	// limits of query complexity, zero means no limit
+	maxDepth         int
+	maxClauseCount   int
+	maxTermGroupSize int
+	maxSqlLength     int
+
+	// states of current conversion, which are reset by LuceneToSql
+	depth    int
+	clauses  int
+	warnings []*Warning
+	scores   []string
+	boost    float64 // product of boosts of term and enclosing term groups
+	locator  *clauseLocator
+
+	// explanation is built if explain is on, see Explain
+	explain      bool
+	explainRoot  *ExplainNode
+	explainStack []*ExplainNode
 }
 
 func WithTokenizer(field string, tokenizer Tokenizer) func(s *SqlConvertor) {
@@ -65,6 +163,207 @@ func WithSchema(mappings *esMapping.PropertyMapping) func(s *SqlConvertor) {
 	}
 }
 
+// WithArrayField declares fields are stored as array column, term is matched with every element of array.
+func WithArrayField(fields ...string) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		for _, field := range fields {
+			s.arrayFields[field] = true
+		}
+	}
+}
+
+// WithMapField binds field prefix to a map column, sub field of prefix (e.g. labels.env) is the key of map.
+func WithMapField(prefix string, mapField *MapField) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.mapFields[prefix] = mapField
+	}
+}
+
+// WithMaxInListSize sets max number of elements in one IN list, longer list is split into several IN lists.
+func WithMaxInListSize(size int) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.maxInListSize = size
+	}
+}
+
+// WithDefaultFields sets fields which are queried by term without field (e.g. foo / "foo bar"),
+// the term is expanded to OR of the queries on every field. "*" means all text / keyword fields in schema.
+func WithDefaultFields(fields ...string) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.defaultFields = fields
+	}
+}
+
+// WithCaseInsensitiveRegexp makes regexp query match case insensitively, e.g. ~* of PostgreSQL.
+func WithCaseInsensitiveRegexp(caseInsensitive bool) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.caseInsensitiveRegexp = caseInsensitive
+	}
+}
+
+// WithFuzzyStrategy sets strategy of fuzzy query (e.g. foo~1).
+func WithFuzzyStrategy(strategy FUZZY_STRATEGY) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.fuzzyStrategy = strategy
+	}
+}
+
+// WithLevenshteinFunc sets name of function which computes edit distance of two strings,
+// it's required by EditDistance strategy in sql style which has no builtin function.
+func WithLevenshteinFunc(name string) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.levenshteinFunc = name
+	}
+}
+
+// WithTrigramThreshold sets min similarity of Trigram strategy, e.g. 0.3
+func WithTrigramThreshold(threshold float64) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.trigramThreshold = threshold
+	}
+}
+
+// WithFuzzyTranspositions sets whether transposition of two adjacent chars is counted as one edit.
+func WithFuzzyTranspositions(transpositions bool) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.fuzzyTranspositions = transpositions
+	}
+}
+
+// WithAutoFuzziness makes fuzziness of ~ without number depend on length of term,
+// it's 0 for 0-2 chars, 1 for 3-5 chars and 2 for more chars like AUTO of ES.
+func WithAutoFuzziness(auto bool) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.autoFuzziness = auto
+	}
+}
+
+// WithPrefixAsRange converts prefix query (e.g. abc*) to range (e.g. f >= 'abc' AND f < 'abd')
+// or startsWith of ClickHouse, which can be used by index.
+func WithPrefixAsRange(prefixAsRange bool) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.prefixAsRange = prefixAsRange
+	}
+}
+
+// WithAllowLeadingWildcard sets whether wildcard query can start with * or ?, e.g. *error
+func WithAllowLeadingWildcard(allow bool) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.allowLeadingWildcard = allow
+	}
+}
+
+// WithAllowExpensiveQueries sets whether wildcard, regexp and fuzzy query are allowed.
+func WithAllowExpensiveQueries(allow bool) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.allowExpensiveQueries = allow
+	}
+}
+
+// WithMaxDeterminizedStates limits states of determinized automaton of regexp like max_determinized_states of ES,
+// zero means no limit.
+func WithMaxDeterminizedStates(states int) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.maxDeterminizedStates = states
+	}
+}
+
+// WithFieldPolicy sets policy which decides fields can be queried, it can be used as option of single call.
+func WithFieldPolicy(policy *FieldPolicy) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.fieldPolicy = policy
+	}
+}
+
+// WithLenient makes clause whose value doesn't match type of field (e.g. age:abc) match nothing
+// rather than fail conversion, like lenient of ES.
+func WithLenient(lenient bool) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.lenient = lenient
+	}
+}
+
+// WithFullTextRank makes score of term on text field (e.g. body:foo / body:"foo bar") be rank of full text search,
+// i.e. ts_rank of PostgreSQL and MATCH AGAINST of MySQL, predicates aren't changed. it only works with WithScoring.
+func WithFullTextRank(rank bool) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.rankFullText = rank
+	}
+}
+
+// WithScoring makes Convert return expression of relevance score by boosts of clauses, see Result.Score.
+func WithScoring(scoring bool) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.scoring = scoring
+	}
+}
+
+// WithFormat formats sql with canonical keyword casing and minimal parens, and lays it out in multiple lines optionally.
+func WithFormat(format *SqlFormat) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.format = format
+	}
+}
+
+// WithUnknownFieldPolicy sets policy which decides how to query field isn't in schema, default is strict.
+func WithUnknownFieldPolicy(policy *UnknownFieldPolicy) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.unknownFieldPolicy = policy
+	}
+}
+
+// WithFilter adds mandatory predicate which is ANDed with every query, ? is the placeholder of args,
+// e.g. WithFilter("tenant_id = ?", 42).
+func WithFilter(predicate string, args ...interface{}) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.filters = append(s.filters, &Filter{Predicate: predicate, Args: args})
+	}
+}
+
+// WithFilterFunc adds function which computes mandatory predicates of every call from context.
+func WithFilterFunc(filterFunc FilterFunc) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.filterFuncs = append(s.filterFuncs, filterFunc)
+	}
+}
+
+// WithMaxDepth limits depth of nested parentheses (including term group) in query.
+func WithMaxDepth(depth int) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.maxDepth = depth
+	}
+}
+
+// WithMaxClauseCount limits number of clauses in query, like indices.query.bool.max_clause_count of ES,
+// the term on several fields (e.g. default fields) is counted as several clauses.
+func WithMaxClauseCount(count int) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.maxClauseCount = count
+	}
+}
+
+// WithMaxTermGroupSize limits number of terms in one term group, e.g. field:(a OR b OR c)
+func WithMaxTermGroupSize(size int) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.maxTermGroupSize = size
+	}
+}
+
+// WithMaxSqlLength limits length of generated sql.
+func WithMaxSqlLength(length int) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.maxSqlLength = length
+	}
+}
+
+// WithDefaultOperator sets operator which joins clauses without explicit operator (e.g. foo bar)
+// and the tokens split by tokenizer of text field, like default_operator of ES.
+func WithDefaultOperator(operator OPERATOR) func(s *SqlConvertor) {
+	return func(s *SqlConvertor) {
+		s.defaultOperator = operator
+	}
+}
+
 func WithSQLStyle(sqlStyle SQL_STYLE) func(s *SqlConvertor) {
 	return func(s *SqlConvertor) {
 		s.sqlStyle = sqlStyle
@@ -72,43 +371,192 @@ func WithSQLStyle(sqlStyle SQL_STYLE) func(s *SqlConvertor) {
 }
 
 func NewSqlConvertor(options ...func(s *SqlConvertor)) *SqlConvertor {
-	s := &SqlConvertor{tokenizers: make(map[string]Tokenizer)}
+	s := &SqlConvertor{
+		tokenizers:          make(map[string]Tokenizer),
+		arrayFields:         make(map[string]bool),
+		mapFields:           make(map[string]*MapField),
+		fuzzyTranspositions: true,
+		// same as default of ES
+		allowLeadingWildcard:  true,
+		allowExpensiveQueries: true,
+	}
 	for _, opt := range options {
 		opt(s)
 	}
 	return s
 }
 
-func (c *SqlConvertor) LuceneToSql(query string) (string, error) {
-	lucene, err := lucene_parser.ParseLucene(query)
+// LuceneToSql converts lucene query to sql, options (e.g. WithFieldPolicy) are only applied to this call.
+// args of filters are inlined as literals.
+func (c *SqlConvertor) LuceneToSql(query string, options ...func(s *SqlConvertor)) (string, error) {
+	res, err := c.luceneToSqlWithFilters(context.Background(), query, true, options)
 	if err != nil {
 		return "", err
 	}
-	return c.luceneToSql(lucene)
+	return res.SQL, nil
+}
+
+// LuceneToSqlContext converts lucene query to sql like LuceneToSql, filters are computed with ctx
+// and args of filters are returned as parameters of sql.
+func (c *SqlConvertor) LuceneToSqlContext(
+	ctx context.Context, query string, options ...func(s *SqlConvertor),
+) (string, []interface{}, error) {
+	res, err := c.luceneToSqlWithFilters(ctx, query, false, options)
+	if err != nil {
+		return "", nil, err
+	}
+	return res.SQL, res.Args, nil
+}
+
+func (c *SqlConvertor) luceneToSqlWithFilters(
+	ctx context.Context, query string, inline bool, options []func(s *SqlConvertor),
+) (*Result, error) {
+	// states of conversion and options are kept in copy, so that convertor can be used concurrently
+	cvt := c.clone()
+	for _, opt := range options {
+		opt(cvt)
+	}
+	return cvt.convert(ctx, query, inline)
+}
+
+// convert converts query to sql with filters, it should be called on copy of convertor.
+func (c *SqlConvertor) convert(ctx context.Context, query string, inline bool) (*Result, error) {
+	// query is parsed once by rewriter, parsed clauses locate the clauses of conversion in query
+	level, rewritten := parseQueryLevel(query, c.defaultOperator), query
+	if level != nil {
+		rewritten = level.String()
+	}
+	lucene, err := lucene_parser.ParseLucene(rewritten)
+	if err != nil {
+		return nil, newParseError(query, rewritten, c.defaultOperator, err)
+	}
+	c.locator = newClauseLocator(level)
+	sql, err := c.luceneToSql(lucene)
+	if err != nil {
+		locateError(err, c.locator)
+		return nil, err
+	} else if sql == "" {
+		// all of clauses are dropped
+		sql = truePredicate
+	}
+	if level != nil {
+		c.convertDroppedShoulds(query, level.droppedShoulds(nil))
+	}
+	sql, args, err := c.filtersToSql(ctx, sql, inline)
+	if err != nil {
+		return nil, err
+	}
+	if c.format != nil {
+		sql = c.format.formatSql(sql)
+	}
+	if err := c.checkSqlLength(sql); err != nil {
+		return nil, err
+	}
+	locateWarnings(c.warnings, c.locator)
+	return &Result{SQL: sql, Args: args, Score: c.scoreToSql(), Warnings: c.warnings}, nil
 }
 
-func (c *SqlConvertor) luceneToSql(lucene *lucene_parser.Lucene) (string, error) {
+// convertDroppedShoulds warns of should clauses which are dropped from predicate because of must clauses,
+// and converts them for their scores (e.g. title:b of +name:a title:b^2 like lucene) and explanation,
+// predicates, warnings and errors of their conversion are discarded.
+func (c *SqlConvertor) convertDroppedShoulds(query string, dropped []*droppedClause) {
+	for _, clause := range dropped {
+		text := query[clause.offset:clause.end]
+		c.warnings = append(c.warnings, &Warning{
+			Kind:    ShouldClauseDropped,
+			Clause:  Clause{Field: clause.fieldName(), Term: text[len(clause.field):], Offset: clause.offset},
+			Message: fmt.Sprintf("should clause: %s is dropped by required clauses, it only affects score", text),
+		})
+	}
+	if !c.scoring && !c.explain {
+		return
+	}
+	warnings, clauses, stack := len(c.warnings), c.clauses, c.explainStack
+	defer func() {
+		c.warnings, c.clauses, c.explainStack = c.warnings[:warnings], clauses, stack
+	}()
+	var nodes []*ExplainNode
+	for _, clause := range dropped {
+		scores, holder := len(c.scores), &ExplainNode{}
+		c.explainStack = []*ExplainNode{holder}
+		lucene, err := lucene_parser.ParseLucene(clause.String())
+		if err == nil {
+			_, err = c.luceneToSql(lucene)
+		}
+		if err != nil {
+			c.scores = c.scores[:scores]
+		}
+		if !c.explain {
+			continue
+		}
+		// clause which can't be converted (e.g. boost of sub query) is explained without sql
+		node := &ExplainNode{Kind: ExplainTerm}
+		if clause.subQuery != nil {
+			node.Kind = ExplainGroup
+		}
+		if err == nil {
+			node = simplifyExplain(holder.Children[0])
+		}
+		node.Query, node.Dropped = query[clause.offset:clause.end], true
+		node.Offset, node.End = clause.offset, clause.end
+		nodes = append(nodes, node)
+	}
+	if len(nodes) != 0 {
+		// dropped clauses are siblings of the clauses of predicate in boolean query of lucene
+		c.explainRoot = &ExplainNode{
+			Kind: ExplainAnd, SQL: c.explainRoot.SQL, Children: append([]*ExplainNode{c.explainRoot}, nodes...),
+		}
+	}
+}
+
+// clone copies convertor without states of conversion.
+func (c *SqlConvertor) clone() *SqlConvertor {
+	cvt := *c
+	cvt.tokenizers = make(map[string]Tokenizer, len(c.tokenizers))
+	for field, tokenizer := range c.tokenizers {
+		cvt.tokenizers[field] = tokenizer
+	}
+	cvt.arrayFields = make(map[string]bool, len(c.arrayFields))
+	for field := range c.arrayFields {
+		cvt.arrayFields[field] = true
+	}
+	cvt.mapFields = make(map[string]*MapField, len(c.mapFields))
+	for prefix, mapField := range c.mapFields {
+		cvt.mapFields[prefix] = mapField
+	}
+	cvt.filters = append([]*Filter(nil), c.filters...)
+	cvt.filterFuncs = append([]FilterFunc(nil), c.filterFuncs...)
+	cvt.depth, cvt.clauses, cvt.warnings = 0, 0, nil
+	cvt.scores, cvt.boost = nil, 1
+	cvt.explainRoot, cvt.explainStack = nil, nil
+	cvt.locator = nil
+	return &cvt
+}
+
+func (c *SqlConvertor) luceneToSql(lucene *lucene_parser.Lucene) (res string, err error) {
+	defer c.explainNode(&ExplainNode{Kind: ExplainOr})(&res)
 	sql := NewSQL()
 	str, err := c.orQueryToSql(lucene.OrQuery)
 	if err != nil {
-		return "", fmt.Errorf("failed to convert OR clause, err: %w", err)
+		return "", err
 	}
 	sql.AddORClause(str, false)
 	for _, subQuery := range lucene.OSQuery {
 		str, err = c.orQueryToSql(subQuery.OrQuery)
 		if err != nil {
-			return "", fmt.Errorf("failed to convert OR clause, err: %w", err)
+			return "", err
 		}
 		sql.AddORClause(str, true)
 	}
 	return sql.String(), nil
 }
 
-func (c *SqlConvertor) orQueryToSql(orQuery *lucene_parser.OrQuery) (string, error) {
+func (c *SqlConvertor) orQueryToSql(orQuery *lucene_parser.OrQuery) (res string, err error) {
+	defer c.explainNode(&ExplainNode{Kind: ExplainAnd})(&res)
 	sql := NewSQL()
 	str, err := c.andQueryToSql(orQuery.AndQuery)
 	if err != nil {
-		return "", fmt.Errorf("failed to convert AND clause, err: %w", err)
+		return "", err
 	}
 	sql.AddAndClause(str, false, false)
 	for _, subQuery := range orQuery.AnSQuery {
@@ -118,24 +566,30 @@ func (c *SqlConvertor) orQueryToSql(orQuery *lucene_parser.OrQuery) (string, err
 		}
 		str, err = c.andQueryToSql(subQuery.AndQuery)
 		if err != nil {
-			return "", fmt.Errorf("failed to convert AND clause, err: %w", err)
+			return "", err
 		}
 		sql.AddAndClause(str, true, reverse)
 	}
 	return sql.String(), nil
 }
 
-func (c *SqlConvertor) andQueryToSql(andQuery *lucene_parser.AndQuery) (string, error) {
+func (c *SqlConvertor) andQueryToSql(andQuery *lucene_parser.AndQuery) (res string, err error) {
 	reverse := false
 	if andQuery.NotSymbol != nil {
 		reverse = true
 	}
 	if andQuery.ParenQuery != nil {
+		defer c.explainNode(&ExplainNode{Kind: ExplainGroup, Negated: reverse})(&res)
 		sql := NewSQL()
+		scores := len(c.scores)
 		str, err := c.parenToSql(andQuery.ParenQuery)
 		if err != nil {
 			return "", err
 		}
+		if reverse {
+			// prohibited clauses don't affect score
+			c.scores = c.scores[:scores]
+		}
 		sql.AddSubClause(str, reverse)
 		return sql.String(), nil
 	} else {
@@ -144,43 +598,145 @@ func (c *SqlConvertor) andQueryToSql(andQuery *lucene_parser.AndQuery) (string,
 }
 
 func (c *SqlConvertor) parenToSql(parenQuery *lucene_parser.ParenQuery) (string, error) {
+	if err := c.enterParen(); err != nil {
+		return "", err
+	}
+	defer c.leaveParen()
 	str, err := c.luceneToSql(parenQuery.SubQuery)
-	if err != nil {
+	if err != nil || str == "" {
 		return "", err
 	}
 	return fmt.Sprintf("( %s )", str), nil
 }
 
-func (c *SqlConvertor) termQueryToSql(termQuery *lucene_parser.FieldQuery, reverse bool) (string, error) {
+func (c *SqlConvertor) termQueryToSql(termQuery *lucene_parser.FieldQuery, reverse bool) (res string, err error) {
 	// here field must be none empty, because query can be parsed by LuceneParser correctly.
 	field := termQuery.Field.String()
 	value := termQuery.Term
-	typMap, tErr := c.mappings.GetProperty(field)
-	if tErr != nil || len(typMap) == 0 {
-		return "", fmt.Errorf("failed to get field: %s property, err: %v", field, tErr)
+	n := len(c.warnings)
+	if reverse {
+		// prohibited clause doesn't affect score, e.g. term on default fields / term group
+		defer func(scores int) { c.scores = c.scores[:scores] }(len(c.scores))
+	}
+	if stripped, boost := stripBoost(value); boost != "" {
+		if !c.scoring {
+			c.warn(BoostIgnored, "boost: %s only affects score, it's ignored", boost)
+		}
+		defer c.multiplyBoost(term.GetBoostValue(boost).Float())()
+		value = stripped
+	}
+	// field of exists query is the term, e.g. _exists_:field, term without field has no queried field
+	queriedField := field
+	if field == existsField {
+		queriedField = value.String()
+	} else if field == defaultFieldPlaceholder {
+		queriedField = ""
 	}
-	tType := typMap[field]
+	defer c.setWarningClause(n, queriedField, termQuery.Term.String())
+	explained := &ExplainNode{Kind: ExplainTerm, Query: termQuery.Term.String(), Negated: reverse, clauseTerm: termQuery.Term.String()}
+	if field != defaultFieldPlaceholder {
+		explained.Query, explained.clauseField = field+":"+explained.Query, field
+	}
+	defer c.explainNode(explained)(&res)
 	var sql string
-	var err error
-	switch {
-	case value.GetTermType()&term.REGEXP_TERM_TYPE == term.REGEXP_TERM_TYPE:
-		sql, err = c.regexpQueryToSql(field, tType, termQuery.Term)
-	case value.GetTermType()&term.RANGE_TERM_TYPE == term.RANGE_TERM_TYPE:
-		sql, err = c.rangeQueryToSql(field, tType, termQuery.Term)
-	case value.GetTermType()&term.WILDCARD_TERM_TYPE == term.WILDCARD_TERM_TYPE:
-		sql, err = c.wildcardQueryToSql(field, tType, termQuery.Term)
-	case value.GetTermType()&term.FUZZY_TERM_TYPE == term.FUZZY_TERM_TYPE:
-		sql, err = c.fuzzyQueryToSql(field, tType, termQuery.Term)
-	case value.GetTermType()&term.SINGLE_TERM_TYPE == term.SINGLE_TERM_TYPE:
-		sql, err = c.singleQueryToSql(field, tType, termQuery.Term)
-	case value.GetTermType()&term.PHRASE_TERM_TYPE == term.PHRASE_TERM_TYPE:
-		sql, err = c.phraseQueryToSql(field, tType, termQuery.Term)
-	case value.GetTermType()&term.GROUP_TERM_TYPE == term.GROUP_TERM_TYPE:
-		lucene := lucene_parser.TermGroupToLucene(termQuery.Field, value.TermGroup)
-		sql, err = c.luceneToSql(lucene)
+	if field == defaultFieldPlaceholder {
+		var fields []string
+		if fields, err = c.getDefaultFields(); err != nil {
+			return "", fmt.Errorf("failed to get default fields of term: %s, err: %w", value.String(), err)
+		}
+		c.explainTerm("", "", nil, "multi_field")
+		sql, err = c.multiFieldQueryToSql(fields, value)
+	} else if pattern, ok := fieldPattern(field); ok {
+		fields := c.allowedFields(c.matchFields(pattern))
+		if len(fields) == 0 {
+			return "", &UnknownFieldError{Clause: Clause{Field: pattern, Term: value.String()}}
+		}
+		c.explainTerm(pattern, "", nil, "multi_field")
+		sql, err = c.multiFieldQueryToSql(fields, value)
+	} else if denied, dErr := c.checkFieldPolicy(queriedField); denied {
+		c.explainTerm(queriedField, "", nil, "denied")
+		sql, err = falsePredicate, dErr
+	} else if field == existsField {
+		if err := c.addClauses(1); err != nil {
+			return "", err
+		}
+		c.explainTerm(queriedField, "", nil, "exists")
+		sql, err = c.existsQueryToSql(value.String())
+		if err == nil && !reverse {
+			c.addScore(sql, "")
+		}
+	} else if value.GetTermType()&term.GROUP_TERM_TYPE == term.GROUP_TERM_TYPE {
+		if err := c.checkTermGroupSize(field, value.TermGroup); err != nil {
+			return "", err
+		}
+		if err := c.enterParen(); err != nil {
+			return "", err
+		}
+		defer c.leaveParen()
+		var ok bool
+		if sql, ok, err = c.termGroupToInSql(field, value.TermGroup, reverse); err == nil && ok {
+			if err := c.addClauses(countGroupTerms(value.TermGroup.LogicTermGroup)); err != nil {
+				return "", err
+			}
+			if !reverse {
+				c.addScore(sql, "")
+			}
+			c.explainTerm(field, "", nil, "in_list")
+			return sql, nil
+		} else if err == nil {
+			c.explainTerm(field, "", nil, "term_group")
+			lucene := lucene_parser.TermGroupToLucene(termQuery.Field, value.TermGroup)
+			sql, err = c.luceneToSql(lucene)
+			if err == nil && sql != "" && countGroupTerms(value.TermGroup.LogicTermGroup) > 1 {
+				sql = fmt.Sprintf("( %s )", sql)
+			}
+		}
+	} else {
+		if err := c.addClauses(1); err != nil {
+			return "", err
+		}
+		if err := c.checkExpensiveQuery(field, value); err != nil {
+			setClause(err, field, termQuery.Term.String())
+			return "", err
+		}
+		column, tType, rErr := c.resolveField(field)
+		switch {
+		case rErr != nil:
+			c.explainTerm(field, "", nil, "ignored")
+			err = rErr
+		case !c.arrayFields[field] && isUnboundedRange(value):
+			c.explainTerm(field, column, tType, "exists")
+			sql, err = c.existsQueryToSql(field)
+		case c.arrayFields[field]:
+			c.explainTerm(field, column, tType, c.translatorOf(field, tType, value))
+			sql, err = c.arrayQueryToSql(column, tType, value)
+		default:
+			c.explainTerm(field, column, tType, c.translatorOf(field, tType, value))
+			sql, err = c.valueQueryToSql(column, tType, value)
+		}
+		var mismatch *TypeMismatchError
+		if c.lenient && errors.As(err, &mismatch) {
+			c.warn(LenientDropped, "clause matches nothing, err: %v", err)
+			c.explainTerm(field, column, tType, "lenient")
+			sql, err = falsePredicate, nil
+		} else if err == nil && !reverse && sql != "" {
+			var rank string
+			if !c.arrayFields[field] {
+				rank = c.fullTextRank(column, tType, value)
+			}
+			c.addScore(sql, rank)
+		}
 	}
-	if err != nil {
+	if errors.Is(err, errFieldIgnored) {
+		// clause on unknown field matches nothing like unmapped field of ES
+		sql, err = falsePredicate, nil
+	} else if err != nil {
+		setClause(err, queriedField, termQuery.Term.String())
 		return "", err
+	} else if sql == "" {
+		// clause which is converted to nothing matches nothing, it can't be dropped
+		// otherwise NOT / OR around it would match everything
+		sql = falsePredicate
 	}
 	if reverse {
 		return fmt.Sprintf("NOT ( %s )", sql), nil
@@ -188,43 +744,198 @@ func (c *SqlConvertor) termQueryToSql(termQuery *lucene_parser.FieldQuery, rever
 	return sql, nil
 }
 
+// multiFieldQueryToSql converts the term on several fields to OR of the queries on every field,
+// the field is skipped if term can't be converted on it (e.g. foo on number field), like lenient of ES.
+func (c *SqlConvertor) multiFieldQueryToSql(fields []string, value *term.Term) (string, error) {
+	sql := NewSQL()
+	var firstErr error
+	var clauses int
+	var lenientFalse bool
+	for _, field := range fields {
+		str, err := c.termQueryToSql(&lucene_parser.FieldQuery{
+			Field: &term.Field{Value: []string{field}},
+			Term:  value,
+		}, false)
+		if isLimitError(err) {
+			return "", err
+		} else if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		} else if str == "" {
+			continue
+		} else if str == falsePredicate {
+			// value doesn't match type of field in lenient mode
+			lenientFalse = true
+			continue
+		}
+		sql.AddORClause(str, clauses != 0)
+		clauses++
+	}
+	switch clauses {
+	case 0:
+		if lenientFalse {
+			return falsePredicate, nil
+		}
+		return "", firstErr
+	case 1:
+		// query of one field is already parenthesised if it has several clauses, e.g. term group
+		return sql.String(), nil
+	default:
+		return fmt.Sprintf("( %s )", sql.String()), nil
+	}
+}
+
+// getDefaultFields returns fields of term without field.
+func (c *SqlConvertor) getDefaultFields() ([]string, error) {
+	var fields []string
+	for _, field := range c.defaultFields {
+		switch {
+		case field == allFields:
+			for name, property := range c.schemaFields() {
+				if esMapping.CheckStringType(property.Type) {
+					fields = append(fields, name)
+				}
+			}
+		case strings.ContainsAny(field, "*?"):
+			fields = append(fields, c.matchFields(field)...)
+		default:
+			fields = append(fields, field)
+		}
+	}
+	if fields = c.allowedFields(fields); len(fields) == 0 {
+		return nil, fmt.Errorf("default fields are empty")
+	}
+	return uniqueFields(fields), nil
+}
+
+// resolveField returns the column expression and property of field.
+func (c *SqlConvertor) resolveField(field string) (string, *esMapping.Property, error) {
+	if prefix, key, ok := c.splitMapField(field); ok {
+		tType := c.mapKeyProperty(field, c.mapFields[prefix])
+		return c.mapValueToSql(prefix, key, c.mapFields[prefix], tType), tType, nil
+	}
+	tType, err := c.getProperty(field)
+	if err != nil {
+		return c.unknownFieldProperty(field, err)
+	}
+	return field, tType, nil
+}
+
+// getProperty returns property of field in schema.
+func (c *SqlConvertor) getProperty(field string) (*esMapping.Property, error) {
+	if c.mappings == nil {
+		// every field is unknown without schema
+		return nil, &UnknownFieldError{Clause: Clause{Field: field}}
+	}
+	typMap, err := c.mappings.GetProperty(field)
+	if err != nil {
+		return nil, err
+	} else if len(typMap) == 0 {
+		return nil, &UnknownFieldError{Clause: Clause{Field: field}}
+	}
+	return typMap[field], nil
+}
+
+const existsField = "_exists_"
+
+// existsQueryToSql converts _exists_:field to sql which checks field has value.
+func (c *SqlConvertor) existsQueryToSql(field string) (string, error) {
+	if prefix, key, ok := c.splitMapField(field); ok {
+		return c.mapContainsToSql(prefix, key, c.mapFields[prefix]), nil
+	}
+	if _, err := c.getProperty(field); err != nil {
+		return c.unknownExistsToSql(field, err)
+	}
+	return fmt.Sprintf("%s IS NOT NULL", field), nil
+}
+
+// getTermType returns type of term, wildcard at the beginning of term (e.g. *foo) is missed by lucene_parser.
+func getTermType(value *term.Term) term.TermType {
+	termType := value.GetTermType()
+	if value.FuzzyTerm != nil && isWildcard(value.FuzzyTerm.SingleTerm) {
+		termType |= term.WILDCARD_TERM_TYPE
+	}
+	return termType
+}
+
+// isUnboundedRange checks whether term is range without bounds, e.g. [* TO *]
+func isUnboundedRange(value *term.Term) bool {
+	if value.GetTermType()&term.RANGE_TERM_TYPE != term.RANGE_TERM_TYPE {
+		return false
+	}
+	bnd := value.GetBound()
+	return bnd.LeftValue.IsInf(0) && bnd.RightValue.IsInf(0)
+}
+
+func isWildcard(t *term.SingleTerm) bool {
+	if t == nil {
+		return false
+	}
+	return t.Begin == "*" || t.Begin == "?" || t.GetTermType()&term.WILDCARD_TERM_TYPE == term.WILDCARD_TERM_TYPE
+}
+
+func (c *SqlConvertor) valueQueryToSql(
+	field string, tType *esMapping.Property, value *term.Term,
+) (string, error) {
+	termType := getTermType(value)
+	switch {
+	case termType&term.REGEXP_TERM_TYPE == term.REGEXP_TERM_TYPE:
+		return c.regexpQueryToSql(field, tType, value)
+	case termType&term.RANGE_TERM_TYPE == term.RANGE_TERM_TYPE:
+		return c.rangeQueryToSql(field, tType, value)
+	case termType&term.WILDCARD_TERM_TYPE == term.WILDCARD_TERM_TYPE:
+		return c.wildcardQueryToSql(field, tType, value)
+	case termType&term.FUZZY_TERM_TYPE == term.FUZZY_TERM_TYPE:
+		return c.fuzzyQueryToSql(field, tType, value)
+	case termType&term.SINGLE_TERM_TYPE == term.SINGLE_TERM_TYPE:
+		return c.singleQueryToSql(field, tType, value)
+	case termType&term.PHRASE_TERM_TYPE == term.PHRASE_TERM_TYPE:
+		return c.phraseQueryToSql(field, tType, value)
+	default:
+		return "", nil
+	}
+}
+
 func (c *SqlConvertor) singleQueryToSql(
 	field string, tType *esMapping.Property, value *term.Term,
 ) (string, error) {
 	switch {
-	case esMapping.CheckNumberType(tType.Type):
-		return fmt.Sprintf("%s = %s", field, value.String()), nil
-	case esMapping.CheckKeywordType(tType.Type) ||
+	case esMapping.CheckNumberType(tType.Type) ||
+		esMapping.CheckKeywordType(tType.Type) ||
 		esMapping.CheckIPType(tType.Type) ||
-		esMapping.CheckVersionType(tType.Type):
-		val := strings.ReplaceAll(value.String(), "'", "''")
-		return fmt.Sprintf("%s = '%s'", field, val), nil
+		esMapping.CheckVersionType(tType.Type) ||
+		esMapping.CheckDateType(tType.Type):
+		val, err := literalToSql(tType, value.String())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s = %s", field, val), nil
 	case esMapping.CheckTextType(tType.Type):
 		tokenizer, haveTk := c.tokenizers[field]
 		if haveTk {
 			sql := NewSQL()
-			for i, term := range tokenizer.Split(value.String()) {
-				val := strings.ReplaceAll(term, "'", "''")
-				sql.AddORClause(fmt.Sprintf("%s like '%s%s%s'", field, "%", val, "%"), i != 0)
+			terms := tokenizer.Split(value.String())
+			for i, term := range terms {
+				clause := fmt.Sprintf("%s like %s", field, quoteString("%"+term+"%"))
+				if c.defaultOperator == AND {
+					sql.AddAndClause(clause, i != 0, false)
+				} else {
+					sql.AddORClause(clause, i != 0)
+				}
+			}
+			c.warn(TextMatchedByLike, "text is matched by LIKE of every token")
+			if len(terms) > 1 {
+				return fmt.Sprintf("( %s )", sql.String()), nil
 			}
 			return sql.String(), nil
 		} else {
-			val := strings.ReplaceAll(value.String(), "''", "'")
-			return fmt.Sprintf("%s like '%s%s%s'", field, "%", val, "%"), nil
-		}
-
-	case esMapping.CheckDateType(tType.Type):
-		parser, _ := datemath_parser.NewDateMathParser(
-			datemath_parser.WithFormat(strings.Split(tType.Format, "||")),
-		)
-		val := value.String()
-		tt, err := parser.Parse(val)
-		if err != nil {
-			return "", err
+			c.warn(TextMatchedByLike, "text is matched by LIKE without analysis")
+			return fmt.Sprintf("%s like %s", field, quoteString("%"+value.String()+"%")), nil
 		}
-		return field + " = '" + jodaTime.Format(standardFormat, tt) + "'", nil
 	default:
-		return "", fmt.Errorf("single term not support type: %s query", tType.Type)
+		return "", &UnsupportedTermError{Kind: "single term", Type: tType.Type}
 	}
 }
 
@@ -232,25 +943,21 @@ func (c *SqlConvertor) phraseQueryToSql(
 	field string, tType *esMapping.Property, value *term.Term,
 ) (string, error) {
 	val := strings.Trim(value.String(), "\"")
-	val = strings.ReplaceAll(val, "'", "''")
 	switch {
 	case esMapping.CheckKeywordType(tType.Type) ||
 		esMapping.CheckIPType(tType.Type) ||
-		esMapping.CheckVersionType(tType.Type):
-		return fmt.Sprintf("%s = '%s'", field, val), nil
-	case esMapping.CheckTextType(tType.Type):
-		return fmt.Sprintf("%s like '%s%s%s'", field, "%", val, "%"), nil
-	case esMapping.CheckDateType(tType.Type):
-		parser, _ := datemath_parser.NewDateMathParser(
-			datemath_parser.WithFormat(strings.Split(tType.Format, "||")),
-		)
-		tt, err := parser.Parse(val)
+		esMapping.CheckVersionType(tType.Type) ||
+		esMapping.CheckDateType(tType.Type):
+		lit, err := literalToSql(tType, val)
 		if err != nil {
 			return "", err
 		}
-		return field + " = '" + jodaTime.Format(standardFormat, tt) + "'", nil
+		return fmt.Sprintf("%s = %s", field, lit), nil
+	case esMapping.CheckTextType(tType.Type):
+		c.warn(TextMatchedByLike, "text is matched by LIKE without analysis")
+		return fmt.Sprintf("%s like %s", field, quoteString("%"+val+"%")), nil
 	default:
-		return "", fmt.Errorf("phrase not support type: %s query", tType.Type)
+		return "", &UnsupportedTermError{Kind: "phrase", Type: tType.Type}
 	}
 
 }
@@ -264,7 +971,7 @@ func (c *SqlConvertor) rangeQueryToSql(
 	if lVal := bnd.LeftValue; !lVal.IsInf(0) {
 		if esMapping.CheckNumberType(tType.Type) &&
 			len(bnd.LeftValue.PhraseValue) != 0 {
-			return "", fmt.Errorf("field: %s left bound expect number but got string", field)
+			return "", &TypeMismatchError{Value: lVal.String(), Type: tType.Type}
 		}
 		var val, err = getSqlBound(lVal, tType)
 		if err != nil {
@@ -280,7 +987,7 @@ func (c *SqlConvertor) rangeQueryToSql(
 	if rVal := bnd.RightValue; !rVal.IsInf(0) {
 		if esMapping.CheckNumberType(tType.Type) &&
 			len(bnd.RightValue.PhraseValue) != 0 {
-			return "", fmt.Errorf("field: %s right bound expect number but got string", field)
+			return "", &TypeMismatchError{Value: rVal.String(), Type: tType.Type}
 		}
 		var val, err = getSqlBound(rVal, tType)
 		if err != nil {
@@ -292,6 +999,10 @@ func (c *SqlConvertor) rangeQueryToSql(
 			sql.AddAndClause(fmt.Sprintf("%s < %s", field, val), !bnd.LeftValue.IsInf(0), false)
 		}
 	}
+	if sql.String() == "" {
+		// field:[* TO *] matches the document which has value of field like ES
+		return fmt.Sprintf("%s IS NOT NULL", field), nil
+	}
 	return sql.String(), nil
 }
 
@@ -306,21 +1017,49 @@ func getSqlBound(rVal *term.RangeValue, tType *esMapping.Property) (string, erro
 		val = strings.ReplaceAll(val, "'", "''")
 		val = fmt.Sprintf("'%s'", val)
 	} else if esMapping.CheckDateType(tType.Type) {
-		parser, _ := datemath_parser.NewDateMathParser(
-			datemath_parser.WithFormat(strings.Split(tType.Format, "||")),
-		)
-		val = getRangeValue(rVal)
-		tt, err := parser.Parse(val)
-		if err != nil {
-			return "", err
-		}
-		return "'" + jodaTime.Format(standardFormat, tt) + "'", nil
+		return dateToSql(tType, getRangeValue(rVal))
 	} else {
 		val = rVal.String()
+		if esMapping.CheckNumberType(tType.Type) {
+			if _, err := strconv.ParseFloat(val, 64); err != nil {
+				return "", &TypeMismatchError{Value: val, Type: tType.Type}
+			}
+		}
 	}
 	return val, nil
 }
 
+// literalToSql converts value to sql literal according to field type.
+func literalToSql(tType *esMapping.Property, val string) (string, error) {
+	switch {
+	case esMapping.CheckNumberType(tType.Type):
+		if _, err := strconv.ParseFloat(val, 64); err != nil {
+			return "", &TypeMismatchError{Value: val, Type: tType.Type}
+		}
+		return val, nil
+	case esMapping.CheckDateType(tType.Type):
+		return dateToSql(tType, val)
+	default:
+		return quoteString(val), nil
+	}
+}
+
+// dateToSql parses date value with the formats of field and converts it to standard format.
+func dateToSql(tType *esMapping.Property, val string) (string, error) {
+	parser, _ := datemath_parser.NewDateMathParser(
+		datemath_parser.WithFormat(strings.Split(tType.Format, "||")),
+	)
+	tt, err := parser.Parse(val)
+	if err != nil {
+		return "", &TypeMismatchError{Value: val, Type: tType.Type, Err: err}
+	}
+	return "'" + jodaTime.Format(standardFormat, tt) + "'", nil
+}
+
+func quoteString(val string) string {
+	return "'" + strings.ReplaceAll(val, "'", "''") + "'"
+}
+
 func getRangeValue(rVal *term.RangeValue) string {
 	if len(rVal.SingleValue) != 0 {
 		return rVal.String()
@@ -333,21 +1072,15 @@ func (c *SqlConvertor) regexpQueryToSql(
 	field string, tType *esMapping.Property, value *term.Term,
 ) (string, error) {
 	if esMapping.CheckStringType(tType.Type) {
-		val := strings.Trim(value.String(), "/")
-		val = strings.ReplaceAll(val, "'", "''")
-		switch c.sqlStyle {
-		case SQLite, MySQL:
-			return fmt.Sprintf("%s REGEXP '%s'", field, val), nil
-		case Oracle:
-			return fmt.Sprintf("regexp_like(%s, '%s')", field, val), nil
-		case ClickHouse:
-			return fmt.Sprintf("match(%s, '%s')", field, val), nil
-		default:
-			// sql99 and postgresql
-			return fmt.Sprintf("%s SIMILAR TO '%s'", field, val), nil
+		val := value.String()
+		val = val[1 : len(val)-1]
+		node, err := parseRegexp(val)
+		if err != nil {
+			return "", err
 		}
+		return c.regexpMatchToSql(field, node), nil
 	} else {
-		return "", fmt.Errorf("expect field: %s string type, but: %s", field, tType.Type)
+		return "", &UnsupportedTermError{Kind: "regexp", Type: tType.Type}
 	}
 }
 
@@ -355,6 +1088,12 @@ func (c *SqlConvertor) wildcardQueryToSql(
 	field string, tType *esMapping.Property, value *term.Term,
 ) (string, error) {
 	if esMapping.CheckStringType(tType.Type) {
+		if c.prefixAsRange && value.FuzzyTerm.SingleTerm != nil {
+			tks := append([]string{value.FuzzyTerm.SingleTerm.Begin}, value.FuzzyTerm.SingleTerm.Chars...)
+			if prefix, ok := wildcardPrefix(tks); ok {
+				return c.prefixQueryToSql(field, prefix), nil
+			}
+		}
 		switch c.sqlStyle {
 		case SQLite:
 			val := strings.ReplaceAll(value.String(), "'", "''")
@@ -378,34 +1117,6 @@ func (c *SqlConvertor) wildcardQueryToSql(
 			return fmt.Sprintf("%s LIKE '%s'", field, val), nil
 		}
 	} else {
-		return "", fmt.Errorf("expect field: %s string type, but: %s", field, tType.Type)
-	}
-}
-
-func (c *SqlConvertor) fuzzyQueryToSql(
-	field string, tType *esMapping.Property, value *term.Term,
-) (string, error) {
-	if value.FuzzyTerm.PhraseTerm != nil {
-		return "", fmt.Errorf("don't support phrase fuzzy query")
-	}
-	// Levenshtein Distance
-	fuzziness := int(value.FuzzyTerm.Fuzzy().Float())
-	if fuzziness == -1 {
-		fuzziness = 1
-	}
-	if esMapping.CheckStringType(tType.Type) {
-		val := value.FuzzyTerm.SingleTerm.String()
-		val = strings.ReplaceAll(val, "'", "''")
-		switch c.sqlStyle {
-		case PostgreSQL:
-			// CREATE EXTENSION fuzzystrmatch;
-			return fmt.Sprintf("levenshtein(%s, '%s') <= %d", field, val, fuzziness), nil
-		case ClickHouse:
-			return fmt.Sprintf("multiFuzzyMatchAny(%s, %d, '%s')", field, fuzziness, val), nil
-		default:
-			return "", fmt.Errorf("%s is not support fuzzy query", c.sqlStyle)
-		}
-	} else {
-		return "", fmt.Errorf("expect field: %s string type, but: %s", field, tType.Type)
+		return "", &UnsupportedTermError{Kind: "wildcard", Type: tType.Type}
 	}
 }
@@ -17,6 +17,7 @@ func TestSqlStyle(t *testing.T) {
 	assert.Equal(t, "SQLite", SQLite.String())
 	assert.Equal(t, "Oracle", Oracle.String())
 	assert.Equal(t, "ClickHouse", ClickHouse.String())
+	assert.Equal(t, "SQLServer", SQLServer.String())
 	assert.Equal(t, "SQL99", Standard.String())
 }
 
@@ -178,7 +179,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:/x'x+/",
-			wantSQL: "regexp_like(field, 'x''x+')",
+			wantSQL: "regexp_like(field, '^x''x+$')",
 		},
 		{
 			name: "test regexp ClickHouse",
@@ -193,7 +194,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:/x'x+/",
-			wantSQL: "match(field, 'x''x+')",
+			wantSQL: "match(field, '^x''x+$')",
 		},
 		{
 			name: "test regexp sqlite,mysql",
@@ -208,7 +209,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:/x'x+/",
-			wantSQL: "field REGEXP 'x''x+'",
+			wantSQL: "field REGEXP '^x''x+$'",
 		},
 		{
 			name: "test regexp postgresql",
@@ -223,7 +224,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:/x'x+/",
-			wantSQL: "field SIMILAR TO 'x''x+'",
+			wantSQL: "field ~ '^x''x+$'",
 		},
 		{
 			name: "test wildcard error",
@@ -271,7 +272,7 @@ func TestLuceneToSQL(t *testing.T) {
 			wantSQL: "field LIKE 'x''x_x%'",
 		},
 		{
-			name: "test phrase fuzzy error",
+			name: "test phrase fuzzy without slop",
 			opts: []func(*SqlConvertor){
 				WithSQLStyle(PostgreSQL),
 				WithSchema(getSchema(&esMapping.Mapping{
@@ -283,7 +284,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:\"xx yy\"~",
-			wantErr: true,
+			wantSQL: "field like '%xx yy%'",
 		},
 		{
 			name: "test text fuzzy error",
@@ -328,7 +329,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:you'~2",
-			wantSQL: "multiFuzzyMatchAny(field, 2, 'you''')",
+			wantSQL: "damerauLevenshteinDistance(field, 'you''') <= 2",
 		},
 		{
 			name: "test fuzzy other sql",
@@ -541,7 +542,7 @@ func TestLuceneToSQL(t *testing.T) {
 				WithTokenizer("field", &tokenizer{split: "."}),
 			},
 			query:   "field:keyword1.keyword2",
-			wantSQL: `field like '%keyword1%' OR field like '%keyword2%'`,
+			wantSQL: `( field like '%keyword1%' OR field like '%keyword2%' )`,
 		},
 		{
 			name: "test single date query",
@@ -667,7 +668,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:((\"keyword1\" OR \"keyword2\") AND \"keyword3\" AND NOT keyword4)",
-			wantSQL: `( field like '%keyword1%' OR field like '%keyword2%' ) AND field like '%keyword3%' AND NOT ( field like '%keyword4%' )`,
+			wantSQL: `( ( field like '%keyword1%' OR field like '%keyword2%' ) AND field like '%keyword3%' AND NOT ( field like '%keyword4%' ) )`,
 		},
 		{
 			name: "test group and not query",
@@ -682,7 +683,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:(\"keyword1\" OR \"keyword2\" AND NOT (\"keyword3\" OR keyword4))",
-			wantSQL: `field like '%keyword1%' OR field like '%keyword2%' AND  NOT ( field like '%keyword3%' OR field like '%keyword4%' )`,
+			wantSQL: `( field like '%keyword2%' AND  NOT ( field like '%keyword3%' OR field like '%keyword4%' ) )`,
 		},
 		{
 			name: "test group or not query",
@@ -697,7 +698,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:(\"keyword1\" OR \"keyword2\" OR NOT (\"keyword3\" AND keyword4))",
-			wantSQL: `field like '%keyword1%' OR field like '%keyword2%' OR  NOT ( field like '%keyword3%' AND field like '%keyword4%' )`,
+			wantSQL: `( ( field like '%keyword1%' OR field like '%keyword2%' ) AND  NOT ( field like '%keyword3%' AND field like '%keyword4%' ) )`,
 		},
 		{
 			name: "test and not query",
@@ -712,7 +713,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:(\"keyword1\" OR \"keyword2\" AND NOT \"keyword3\")",
-			wantSQL: `field like '%keyword1%' OR field like '%keyword2%' AND NOT ( field like '%keyword3%' )`,
+			wantSQL: `( field like '%keyword2%' AND NOT ( field like '%keyword3%' ) )`,
 		},
 		{
 			name: "test and ! query",
@@ -727,7 +728,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:keyword1 !field:keyword3",
-			wantSQL: `field like '%keyword1%' AND NOT field like '%keyword3%'`,
+			wantSQL: `field like '%keyword1%' AND NOT ( field like '%keyword3%' )`,
 		},
 		{
 			name: "test or not query",
@@ -742,7 +743,7 @@ func TestLuceneToSQL(t *testing.T) {
 				})),
 			},
 			query:   "field:(\"keyword1\" OR \"keyword2\" OR NOT \"keyword3\")",
-			wantSQL: `field like '%keyword1%' OR field like '%keyword2%' OR NOT ( field like '%keyword3%' )`,
+			wantSQL: `( ( field like '%keyword1%' OR field like '%keyword2%' ) AND NOT ( field like '%keyword3%' ) )`,
 		},
 		{
 			name: "test lucene parse error",
@@ -804,28 +805,1649 @@ func TestLuceneToSQL(t *testing.T) {
 			query:   "( field1:value2 )",
 			wantErr: true,
 		},
-	} {
-		t.Run(tt.name, func(t *testing.T) {
-			cvt := NewSqlConvertor(tt.opts...)
-			got, err := cvt.LuceneToSql(tt.query)
-			if tt.wantErr {
-				assert.NotNil(t, err)
-			} else {
-				assert.Equal(t, tt.wantSQL, got)
-			}
-		})
-	}
-}
-
-func getSchema(mapping *esMapping.Mapping) *esMapping.PropertyMapping {
-	res, _ := esMapping.NewPropertyMapping(mapping)
-	return res
-}
-
-type tokenizer struct {
-	split string
-}
-
-func (i *tokenizer) Split(v string) []string {
-	return strings.Split(v, i.split)
+		{
+			name: "test array keyword query ClickHouse",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithArrayField("tags"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "tags:x'y",
+			wantSQL: `has(tags, 'x''y')`,
+		},
+		{
+			name: "test array keyword query PostgreSQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithArrayField("tags"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `tags:"foo bar" AND NOT tags:baz`,
+			wantSQL: `'foo bar' = ANY(tags) AND NOT ( 'baz' = ANY(tags) )`,
+		},
+		{
+			name: "test array number and keyword query MySQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithArrayField("tags", "codes"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+						"codes": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `tags:foo OR codes:200`,
+			wantSQL: `JSON_CONTAINS(tags, JSON_QUOTE('foo')) OR JSON_CONTAINS(codes, '200')`,
+		},
+		{
+			name: "test array wildcard query ClickHouse",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithArrayField("tags"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "tags:fo?b*",
+			wantSQL: `arrayExists(x -> x LIKE 'fo_b%', tags)`,
+		},
+		{
+			name: "test array wildcard query PostgreSQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithArrayField("tags"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "tags:foo*",
+			wantSQL: `EXISTS (SELECT 1 FROM UNNEST(tags) AS t(x) WHERE x LIKE 'foo%')`,
+		},
+		{
+			name: "test array range query SQLite",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithArrayField("codes"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"codes": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "codes:[200 TO 300}",
+			wantSQL: `EXISTS (SELECT 1 FROM json_each(codes) WHERE value >= 200 AND value < 300)`,
+		},
+		{
+			name: "test array range query Oracle",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(Oracle),
+				WithArrayField("codes"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"codes": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "codes:>200",
+			wantSQL: `EXISTS (SELECT 1 FROM JSON_TABLE(codes, '$[*]' COLUMNS (x NUMBER PATH '$')) t WHERE x > 200)`,
+		},
+		{
+			name: "test array keyword query MySQL range",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithArrayField("tags"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "tags:[a TO c]",
+			wantSQL: `EXISTS (SELECT 1 FROM JSON_TABLE(tags, '$[*]' COLUMNS (x VARCHAR(255) PATH '$')) AS t WHERE x >= 'a' AND x <= 'c')`,
+		},
+		{
+			name: "test array group query ClickHouse",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithArrayField("tags"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "tags:(foo AND bar)",
+			wantSQL: `( has(tags, 'foo') AND has(tags, 'bar') )`,
+		},
+		{
+			name: "test array text query Standard",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(Standard),
+				WithArrayField("msgs"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"msgs": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "msgs:foo",
+			wantSQL: `EXISTS (SELECT 1 FROM UNNEST(msgs) AS t(x) WHERE x like '%foo%')`,
+		},
+		{
+			name: "test map field query ClickHouse",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithMapField("labels", &MapField{}),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"labels": {
+							Type: esMapping.FLATTENED_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "labels.env:prod AND labels.app:we*b",
+			wantSQL: `labels['env'] = 'prod' AND labels['app'] LIKE 'we%b'`,
+		},
+		{
+			name: "test map field query with key type ClickHouse",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithMapField("labels", &MapField{DefaultType: esMapping.KEYWORD_FIELD_TYPE}),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"labels": {
+							Mapping: esMapping.Mapping{
+								Properties: map[string]*esMapping.Property{
+									"code": {
+										Type: esMapping.INTEGER_FIELD_TYPE,
+									},
+								},
+							},
+						},
+					},
+				})),
+			},
+			query:   "labels.code:[200 TO 300} OR labels.env:prod",
+			wantSQL: `toFloat64OrNull(labels['code']) >= 200 AND toFloat64OrNull(labels['code']) < 300 OR labels['env'] = 'prod'`,
+		},
+		{
+			name: "test map field query with default type PostgreSQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithMapField("labels", &MapField{DefaultType: esMapping.LONG_FIELD_TYPE}),
+				WithMapField("attrs", &MapField{Hstore: true}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   "labels.code:200 AND attrs.env:prod",
+			wantSQL: `CAST(labels ->> 'code' AS NUMERIC) = 200 AND attrs -> 'env' = 'prod'`,
+		},
+		{
+			name: "test map field query MySQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithMapField("labels", &MapField{}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   `labels.env:prod`,
+			wantSQL: `labels ->> '$."env"' = 'prod'`,
+		},
+		{
+			name: "test map field query SQLite",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithMapField("labels", &MapField{}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   `labels.env:prod`,
+			wantSQL: `json_extract(labels, '$."env"') = 'prod'`,
+		},
+		{
+			name: "test map field query Oracle",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(Oracle),
+				WithMapField("labels", &MapField{DefaultType: esMapping.INTEGER_FIELD_TYPE}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   `labels.code:>200`,
+			wantSQL: `JSON_VALUE(labels, '$."code"' RETURNING NUMBER) > 200`,
+		},
+		{
+			name: "test exists query",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "_exists_:field AND NOT _exists_:field",
+			wantSQL: `field IS NOT NULL AND NOT ( field IS NOT NULL )`,
+		},
+		{
+			name: "test exists query error",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "_exists_:field1",
+			wantErr: true,
+		},
+		{
+			name: "test map field exists query ClickHouse",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithMapField("labels", &MapField{}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   "_exists_:labels.env",
+			wantSQL: `mapContains(labels, 'env')`,
+		},
+		{
+			name: "test map field exists query PostgreSQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithMapField("labels", &MapField{}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   "_exists_:labels.env",
+			wantSQL: `labels ? 'env'`,
+		},
+		{
+			name: "test map field exists query MySQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithMapField("labels", &MapField{}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   "_exists_:labels.env",
+			wantSQL: `JSON_CONTAINS_PATH(labels, 'one', '$."env"')`,
+		},
+		{
+			name: "test map field exists query SQLite",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithMapField("labels", &MapField{}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   "_exists_:labels.env",
+			wantSQL: `json_type(labels, '$."env"') IS NOT NULL`,
+		},
+		{
+			name: "test map field exists query Oracle",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(Oracle),
+				WithMapField("labels", &MapField{}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   "_exists_:labels.env",
+			wantSQL: `JSON_EXISTS(labels, '$."env"')`,
+		},
+		{
+			name: "test group query to IN list",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"status": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "status:(200 OR 201 OR (204 OR 200))",
+			wantSQL: `status IN (200, 201, 204)`,
+		},
+		{
+			name: "test group query to NOT IN list",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"level": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "NOT level:(\"warn'ing\" OR error) AND level:(info)",
+			wantSQL: `level NOT IN ('warn''ing', 'error') AND level = 'info'`,
+		},
+		{
+			name: "test group query to chunked IN list",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithMaxInListSize(2),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"ip": {
+							Type: esMapping.IP_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "ip:(\"1.1.1.1\" OR \"2.2.2.2\" OR \"3.3.3.3\") AND NOT ip:(\"1.1.1.1\" OR \"2.2.2.2\" OR \"3.3.3.3\")",
+			wantSQL: `( ip IN ('1.1.1.1', '2.2.2.2') OR ip IN ('3.3.3.3') ) AND ( ip NOT IN ('1.1.1.1', '2.2.2.2') AND ip NOT IN ('3.3.3.3') )`,
+		},
+		{
+			name: "test group query with AND not to IN list",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"level": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "level:(warn OR error AND info)",
+			wantSQL: `( level = 'error' AND level = 'info' )`,
+		},
+		{
+			name: "test group query with wildcard not to IN list",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"level": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "level:(warn* OR error)",
+			wantSQL: `( level LIKE 'warn%' OR level = 'error' )`,
+		},
+		{
+			name: "test term without field",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithDefaultFields("message"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"message": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "error",
+			wantSQL: `message like '%error%'`,
+		},
+		{
+			name: "test term without field and default fields is empty",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"message": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "error",
+			wantErr: true,
+		},
+		{
+			name: "test terms without field on several fields",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithDefaultFields("message", "level"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"message": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+						"level": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+						"code": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `"time out" AND NOT (error OR code:500)`,
+			wantSQL: `( level = 'time out' OR message like '%time out%' ) AND  NOT ( ( level = 'error' OR message like '%error%' ) OR code = 500 )`,
+		},
+		{
+			name: "test term without field on all fields",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithDefaultFields("*"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"message": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+						"code": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+						"http": {
+							Mapping: esMapping.Mapping{
+								Properties: map[string]*esMapping.Property{
+									"method": {
+										Type: esMapping.KEYWORD_FIELD_TYPE,
+									},
+								},
+							},
+						},
+					},
+				})),
+			},
+			query:   `NOT get*`,
+			wantSQL: `NOT ( ( http.method GLOB 'get*' OR message GLOB 'get*' ) )`,
+		},
+		{
+			name: "test term without field in term group",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithDefaultFields("message"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"message": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+						"level": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `level:(error OR warn) AND >=abc`,
+			wantSQL: `level IN ('error', 'warn') AND message >= 'abc'`,
+		},
+		{
+			name: "test wildcard field",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"message": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+						"http": {
+							Mapping: esMapping.Mapping{
+								Properties: map[string]*esMapping.Property{
+									"method": {
+										Type: esMapping.KEYWORD_FIELD_TYPE,
+									},
+									"status": {
+										Type: esMapping.INTEGER_FIELD_TYPE,
+									},
+									"request": {
+										Mapping: esMapping.Mapping{
+											Properties: map[string]*esMapping.Property{
+												"time": {
+													Type: esMapping.DATE_FIELD_TYPE,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				})),
+			},
+			query:   `http.*:GET AND NOT *age:timeout`,
+			wantSQL: `http.method = 'GET' AND NOT ( message like '%timeout%' )`,
+		},
+		{
+			name: "test wildcard field with term group",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"status": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+						"status_code": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `status*:(200 OR 201)`,
+			wantSQL: `( status IN (200, 201) OR status_code IN ('200', '201') )`,
+		},
+		{
+			name: "test wildcard field skips field of mismatched type",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"status": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+						"status_code": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `status?code:"ok" OR st*:"ok"`,
+			wantSQL: `status_code = 'ok' OR status_code = 'ok'`,
+		},
+		{
+			name: "test wildcard field matches no field",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"status": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `http.*:500`,
+			wantErr: true,
+		},
+		{
+			name: "test wildcard field of mismatched type",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"status": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `stat*:/ok/`,
+			wantErr: true,
+		},
+		{
+			name: "test default fields with pattern",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithDefaultFields("title*", "body"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"title": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+							Fields: map[string]*esMapping.Property{
+								"raw": {
+									Type: esMapping.KEYWORD_FIELD_TYPE,
+								},
+							},
+						},
+						"body": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `foo`,
+			wantSQL: `( body like '%foo%' OR title like '%foo%' OR title.raw = 'foo' )`,
+		},
+		{
+			name: "test single number query error",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:abc`,
+			wantErr: true,
+		},
+		{
+			name: "test required and prohibited modifier",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `+field:foo -field:bar field:baz`,
+			wantSQL: `field = 'foo' AND NOT ( field = 'bar' )`,
+		},
+		{
+			name: "test should clauses with prohibited modifier",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:foo field:baz -field:bar`,
+			wantSQL: `( field = 'foo' OR field = 'baz' ) AND NOT ( field = 'bar' )`,
+		},
+		{
+			name: "test pure prohibited modifier in sub query",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:foo OR (-field:bar -field:baz)`,
+			wantSQL: `field = 'foo' OR ( NOT ( field = 'bar' ) AND NOT ( field = 'baz' ) )`,
+		},
+		{
+			name: "test modifier in term group",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:(foo bar -baz)`,
+			wantSQL: `( ( field = 'foo' OR field = 'bar' ) AND NOT ( field = 'baz' ) )`,
+		},
+		{
+			name: "test text query with tokenizer and and default operator",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+				WithTokenizer("field", &tokenizer{split: "."}),
+				WithDefaultOperator(AND),
+			},
+			query:   "field:keyword1.keyword2",
+			wantSQL: `( field like '%keyword1%' AND field like '%keyword2%' )`,
+		},
+		{
+			name: "test implicit operator with and default operator",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"foo": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+						"bar": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithDefaultOperator(AND),
+			},
+			query:   "foo:a bar:b",
+			wantSQL: `foo = 'a' AND bar = 'b'`,
+		},
+		{
+			name: "test explicit or with and default operator",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"foo": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+						"bar": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithDefaultOperator(AND),
+			},
+			query:   "foo:a OR bar:b",
+			wantSQL: `foo = 'a' OR bar = 'b'`,
+		},
+		{
+			name: "test regexp with numeric interval mysql",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:/a\.b<1-20>@/`,
+			wantSQL: `field REGEXP '^a\\.b0*([1-9]|1[0-9]|20).*$'`,
+		},
+		{
+			name: "test regexp sql99",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(Standard),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:/a_b.*/`,
+			wantSQL: `field SIMILAR TO 'a\_b%' ESCAPE '\'`,
+		},
+		{
+			name: "test regexp unsupported operator",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:/a.*&.*b/`,
+			wantErr: true,
+		},
+		{
+			name: "test case insensitive regexp postgresql",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithCaseInsensitiveRegexp(true),
+			},
+			query:   `field:/ab.*/`,
+			wantSQL: `field ~* '^ab.*$'`,
+		},
+		{
+			name: "test case insensitive regexp ClickHouse",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithCaseInsensitiveRegexp(true),
+			},
+			query:   `field:/ab.*/`,
+			wantSQL: `match(field, '(?i)^ab.*$')`,
+		},
+		{
+			name: "test case insensitive regexp oracle",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(Oracle),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithCaseInsensitiveRegexp(true),
+			},
+			query:   `field:/ab.*/`,
+			wantSQL: `regexp_like(field, '^ab.*$', 'i')`,
+		},
+		{
+			name: "test case insensitive regexp sql99",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(Standard),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithCaseInsensitiveRegexp(true),
+			},
+			query:   `field:/AB.*/`,
+			wantSQL: `LOWER(field) SIMILAR TO 'ab%' ESCAPE '\'`,
+		},
+		{
+			name: "test proximity phrase postgresql",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:"foo bar"~2`,
+			wantSQL: `to_tsvector(field) @@ to_tsquery('''foo'' <1> ''bar'' | ''foo'' <2> ''bar'' | ''foo'' <3> ''bar'' | ''bar'' <1> ''foo'' | ''bar'' <2> ''foo'' | ''bar'' <3> ''foo''')`,
+		},
+		{
+			name: "test proximity phrase sqlite",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:"foo bar"~3`,
+			wantSQL: `field MATCH 'NEAR("foo" "bar", 3)'`,
+		},
+		{
+			name: "test proximity phrase mysql",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:"foo bar baz"~3`,
+			wantSQL: `MATCH (field) AGAINST ('"foo bar baz" @5' IN BOOLEAN MODE)`,
+		},
+		{
+			name: "test proximity phrase ClickHouse",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:"foo bar"~1`,
+			wantSQL: `match(field, '^(.*[^\\pL\\pN])?(foo([^\\pL\\pN]+[\\pL\\pN]+)?[^\\pL\\pN]+bar|bar([^\\pL\\pN]+[\\pL\\pN]+)?[^\\pL\\pN]+foo)([^\\pL\\pN].*)?$')`,
+		},
+		{
+			name: "test proximity phrase keyword",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:"foo bar"~1`,
+			wantSQL: `field = 'foo bar'`,
+		},
+		{
+			name: "test fuzzy oracle",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(Oracle),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:jon~2`,
+			wantSQL: `UTL_MATCH.EDIT_DISTANCE(field, 'jon') BETWEEN 0 AND 2`,
+		},
+		{
+			name: "test fuzzy levenshtein function",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithLevenshteinFunc("levenshtein_udf"),
+			},
+			query:   `field:jon~1`,
+			wantSQL: `levenshtein_udf(field, 'jon') <= 1`,
+		},
+		{
+			name: "test fuzzy trigram",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithFuzzyStrategy(Trigram),
+			},
+			query:   `field:jon~1`,
+			wantSQL: `field % 'jon'`,
+		},
+		{
+			name: "test fuzzy trigram threshold",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithFuzzyStrategy(Trigram),
+				WithTrigramThreshold(0.4),
+			},
+			query:   `field:jon~1`,
+			wantSQL: `similarity(field, 'jon') >= 0.4`,
+		},
+		{
+			name: "test fuzzy approximate",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithFuzzyStrategy(Approximate),
+			},
+			query:   `field:jon~1`,
+			wantSQL: `field LIKE 'jo%' AND LENGTH(field) BETWEEN 2 AND 4`,
+		},
+		{
+			name: "test fuzzy approximate short term",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithFuzzyStrategy(Approximate),
+			},
+			query:   `field:jo~2`,
+			wantSQL: `CHAR_LENGTH(field) BETWEEN 0 AND 4`,
+		},
+		{
+			name: "test fuzzy trigram other sql",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithFuzzyStrategy(Trigram),
+			},
+			query:   `field:jon~1`,
+			wantErr: true,
+		},
+		{
+			name: "test fuzzy ClickHouse without transpositions",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithFuzzyTranspositions(false),
+			},
+			query:   `field:jon~1`,
+			wantSQL: `editDistance(field, 'jon') <= 1`,
+		},
+		{
+			name: "test auto fuzziness short term",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithAutoFuzziness(true),
+			},
+			query:   `field:jo~`,
+			wantSQL: `damerauLevenshteinDistance(field, 'jo') <= 0`,
+		},
+		{
+			name: "test auto fuzziness middle term",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithAutoFuzziness(true),
+			},
+			query:   `field:jones~`,
+			wantSQL: `damerauLevenshteinDistance(field, 'jones') <= 1`,
+		},
+		{
+			name: "test auto fuzziness long term",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithAutoFuzziness(true),
+			},
+			query:   `field:johnson~`,
+			wantSQL: `levenshtein(field, 'johnson') <= 2`,
+		},
+		{
+			name: "test auto fuzziness with explicit fuzziness",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithAutoFuzziness(true),
+			},
+			query:   `field:johnson~1`,
+			wantSQL: `levenshtein(field, 'johnson') <= 1`,
+		},
+		{
+			name: "test prefix as range",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithPrefixAsRange(true),
+			},
+			query:   `field:abc*`,
+			wantSQL: `field >= 'abc' AND field < 'abd'`,
+		},
+		{
+			name: "test prefix as range ClickHouse",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(ClickHouse),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithPrefixAsRange(true),
+			},
+			query:   `field:abc*`,
+			wantSQL: `startsWith(field, 'abc')`,
+		},
+		{
+			name: "test not prefix as range",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+				WithPrefixAsRange(true),
+			},
+			query:   `field:a?c*`,
+			wantSQL: `field LIKE 'a_c%'`,
+		},
+		{
+			name: "test leading wildcard",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:*abc`,
+			wantSQL: `field LIKE '%abc'`,
+		},
+		{
+			name: "test fuzzy SQLServer without levenshtein function",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLServer),
+				WithCaseInsensitiveRegexp(true),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "field:/ab+/ OR field:foo~1",
+			wantErr: true,
+		},
+		{
+			name: "test regexp and approximate fuzzy SQLServer",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLServer),
+				WithCaseInsensitiveRegexp(true),
+				WithFuzzyStrategy(Approximate),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "field:/ab+/ OR field:foo~1",
+			wantSQL: "REGEXP_LIKE(field, '^ab+$', 'i') OR field LIKE 'fo%' AND LEN(field) BETWEEN 2 AND 4",
+		},
+		{
+			name: "test array number query with quote MySQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithArrayField("codes"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"codes": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `codes:1'`,
+			wantErr: true,
+		},
+		{
+			name: "test text query with quote",
+			opts: []func(*SqlConvertor){
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"body": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+				WithDefaultFields("body"),
+			},
+			query:   `body:fo'o OR ba'r`,
+			wantSQL: `body like '%fo''o%' OR body like '%ba''r%'`,
+		},
+		{
+			name: "test proximity phrase with tsquery operators postgresql",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithTokenizer("field", &tokenizer{split: " "}),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.TEXT_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:"it's a|b"~1`,
+			wantSQL: `to_tsvector(field) @@ to_tsquery('''it''''s'' <1> ''a|b'' | ''it''''s'' <2> ''a|b'' | ''a|b'' <1> ''it''''s'' | ''a|b'' <2> ''it''''s''')`,
+		},
+		{
+			name: "test array query SQLServer",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLServer),
+				WithArrayField("tags", "codes"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+						"codes": {
+							Type: esMapping.INTEGER_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "tags:foo AND codes:[200 TO 300}",
+			wantSQL: `EXISTS (SELECT 1 FROM OPENJSON(tags) WITH (x NVARCHAR(4000) '$') AS t WHERE x = 'foo') AND EXISTS (SELECT 1 FROM OPENJSON(codes) WITH (x FLOAT '$') AS t WHERE x >= 200 AND x < 300)`,
+		},
+		{
+			name: "test map field query SQLServer",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLServer),
+				WithMapField("labels", &MapField{}),
+				WithMapField("metrics", &MapField{DefaultType: esMapping.LONG_FIELD_TYPE}),
+				WithSchema(getSchema(&esMapping.Mapping{})),
+			},
+			query:   `labels.env:prod AND metrics.code:>200 AND _exists_:labels.app`,
+			wantSQL: `JSON_VALUE(labels, '$."env"') = 'prod' AND TRY_CAST(JSON_VALUE(metrics, '$."code"') AS FLOAT) > 200 AND JSON_PATH_EXISTS(labels, '$."app"') = 1`,
+		},
+		{
+			name: "test inline bool filter SQLServer",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLServer),
+				WithFilter("deleted = ?", false),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   "field:foo",
+			wantSQL: `( field = 'foo' ) AND ( deleted = 0 )`,
+		},
+		{
+			name: "test text group query before AND",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"title": {Type: esMapping.TEXT_FIELD_TYPE},
+						"name":  {Type: esMapping.KEYWORD_FIELD_TYPE},
+					},
+				})),
+			},
+			query:   "title:(a OR b) AND name:x",
+			wantSQL: `( title like '%a%' OR title like '%b%' ) AND name = 'x'`,
+		},
+		{
+			name: "test text group query after AND",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"title": {Type: esMapping.TEXT_FIELD_TYPE},
+						"name":  {Type: esMapping.KEYWORD_FIELD_TYPE},
+					},
+				})),
+			},
+			query:   "name:x AND title:(a OR b)",
+			wantSQL: `name = 'x' AND ( title like '%a%' OR title like '%b%' )`,
+		},
+		{
+			name: "test wildcard group query before AND",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"name": {Type: esMapping.KEYWORD_FIELD_TYPE},
+						"age":  {Type: esMapping.INTEGER_FIELD_TYPE},
+					},
+				})),
+			},
+			query:   "name:(a OR b*) AND age:1",
+			wantSQL: `( name = 'a' OR name LIKE 'b%' ) AND age = 1`,
+		},
+		{
+			name: "test array group query before AND PostgreSQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithArrayField("tags"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {Type: esMapping.KEYWORD_FIELD_TYPE},
+						"name": {Type: esMapping.KEYWORD_FIELD_TYPE},
+					},
+				})),
+			},
+			query:   "tags:(a OR b) AND name:x",
+			wantSQL: `( 'a' = ANY(tags) OR 'b' = ANY(tags) ) AND name = 'x'`,
+		},
+		{
+			name: "test array group query after AND PostgreSQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithArrayField("tags"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"tags": {Type: esMapping.KEYWORD_FIELD_TYPE},
+						"name": {Type: esMapping.KEYWORD_FIELD_TYPE},
+					},
+				})),
+			},
+			query:   "name:x AND tags:(a OR b)",
+			wantSQL: `name = 'x' AND ( 'a' = ANY(tags) OR 'b' = ANY(tags) )`,
+		},
+		{
+			name: "test tokenized text query before AND",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"title": {Type: esMapping.TEXT_FIELD_TYPE},
+						"name":  {Type: esMapping.KEYWORD_FIELD_TYPE},
+					},
+				})),
+				WithTokenizer("title", &tokenizer{split: "."}),
+			},
+			query:   "title:foo.bar AND name:x",
+			wantSQL: `( title like '%foo%' OR title like '%bar%' ) AND name = 'x'`,
+		},
+		{
+			name: "test tokenized text query of default fields before AND",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"title": {Type: esMapping.TEXT_FIELD_TYPE},
+						"name":  {Type: esMapping.KEYWORD_FIELD_TYPE},
+					},
+				})),
+				WithTokenizer("title", &tokenizer{split: "."}),
+				WithDefaultFields("title"),
+			},
+			query:   "foo.bar AND name:x",
+			wantSQL: `( title like '%foo%' OR title like '%bar%' ) AND name = 'x'`,
+		},
+		{
+			name: "test tokenized text query of one token",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"title": {Type: esMapping.TEXT_FIELD_TYPE},
+						"name":  {Type: esMapping.KEYWORD_FIELD_TYPE},
+					},
+				})),
+				WithTokenizer("title", &tokenizer{split: "."}),
+			},
+			query:   "title:foo AND name:x",
+			wantSQL: `title like '%foo%' AND name = 'x'`,
+		},
+		{
+			name: "test wildcard field of single match with term group before AND",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"name": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+						"http": {
+							Mapping: esMapping.Mapping{
+								Properties: map[string]*esMapping.Property{
+									"title": {
+										Type: esMapping.TEXT_FIELD_TYPE,
+									},
+								},
+							},
+						},
+					},
+				})),
+			},
+			query:   `http.*:(a OR b) AND name:x`,
+			wantSQL: `( http.title like '%a%' OR http.title like '%b%' ) AND name = 'x'`,
+		},
+		{
+			name: "test wildcard field of single match with term group after AND",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(SQLite),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"name": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+						"http": {
+							Mapping: esMapping.Mapping{
+								Properties: map[string]*esMapping.Property{
+									"title": {
+										Type: esMapping.TEXT_FIELD_TYPE,
+									},
+								},
+							},
+						},
+					},
+				})),
+			},
+			query:   `name:x AND http.*:(a OR b)`,
+			wantSQL: `name = 'x' AND ( http.title like '%a%' OR http.title like '%b%' )`,
+		},
+		{
+			name: "test range without bounds",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"name": {Type: esMapping.KEYWORD_FIELD_TYPE},
+						"age":  {Type: esMapping.INTEGER_FIELD_TYPE},
+					},
+				})),
+			},
+			query:   "age:1 OR name:[* TO *]",
+			wantSQL: `age = 1 OR name IS NOT NULL`,
+		},
+		{
+			name: "test negated range without bounds",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(MySQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"name": {Type: esMapping.KEYWORD_FIELD_TYPE},
+					},
+				})),
+			},
+			query:   "NOT name:[* TO *]",
+			wantSQL: `NOT ( name IS NOT NULL )`,
+		},
+		{
+			name: "test array range without bounds PostgreSQL",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithArrayField("scores"),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"scores": {Type: esMapping.INTEGER_FIELD_TYPE},
+					},
+				})),
+			},
+			query:   "NOT scores:[* TO *]",
+			wantSQL: `NOT ( EXISTS (SELECT 1 FROM UNNEST(scores) AS t(x) WHERE x IS NOT NULL) )`,
+		},
+		{
+			name: "test fuzziness is clamped to max edits",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:jon~5`,
+			wantSQL: `levenshtein(field, 'jon') <= 2`,
+		},
+		{
+			name: "test fractional fuzziness is min similarity",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:jon~0.5`,
+			wantSQL: `levenshtein(field, 'jon') <= 1`,
+		},
+		{
+			name: "test fractional fuzziness of long term is clamped",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:jonathan~0.5`,
+			wantSQL: `levenshtein(field, 'jonathan') <= 2`,
+		},
+		{
+			name: "test zero fuzziness",
+			opts: []func(*SqlConvertor){
+				WithSQLStyle(PostgreSQL),
+				WithSchema(getSchema(&esMapping.Mapping{
+					Properties: map[string]*esMapping.Property{
+						"field": {
+							Type: esMapping.KEYWORD_FIELD_TYPE,
+						},
+					},
+				})),
+			},
+			query:   `field:jon~0`,
+			wantSQL: `levenshtein(field, 'jon') <= 0`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cvt := NewSqlConvertor(tt.opts...)
+			got, err := cvt.LuceneToSql(tt.query)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Equal(t, tt.wantSQL, got)
+			}
+		})
+	}
+}
+
+func getSchema(mapping *esMapping.Mapping) *esMapping.PropertyMapping {
+	res, _ := esMapping.NewPropertyMapping(mapping)
+	return res
+}
+
+type tokenizer struct {
+	split string
+}
+
+func (i *tokenizer) Split(v string) []string {
+	return strings.Split(v, i.split)
+}
+
+func TestOracleInListLimit(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSQLStyle(Oracle),
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"id": {
+					Type: esMapping.LONG_FIELD_TYPE,
+				},
+			},
+		})),
+	)
+	var ids []string
+	for i := 0; i < 1001; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+	got, err := cvt.LuceneToSql(fmt.Sprintf("id:(%s)", strings.Join(ids, " OR ")))
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf("( id IN (%s) OR id IN (1000) )", strings.Join(ids[:1000], ", ")), got)
+}
+
+func TestOverlappedMapFields(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSQLStyle(ClickHouse),
+		WithMapField("labels", &MapField{}),
+		WithMapField("labels.sub", &MapField{}),
+		WithSchema(getSchema(&esMapping.Mapping{})),
+	)
+	// iteration order of map is random, the longest prefix must be chosen every time
+	for i := 0; i < 20; i++ {
+		got, err := cvt.LuceneToSql("labels.sub.env:prod AND labels.env:dev")
+		assert.Nil(t, err)
+		assert.Equal(t, "labels.sub['env'] = 'prod' AND labels['env'] = 'dev'", got)
+	}
 }
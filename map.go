@@ -0,0 +1,111 @@
+package lucene_to_sql
+
+import (
+	"fmt"
+	"strings"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+// MapField describes a map column (e.g. ClickHouse Map(String, String) / PostgreSQL jsonb),
+// whose keys are queried as sub fields of the column, for instance labels.env.
+type MapField struct {
+	// DefaultType is type of key which isn't declared in schema, default is keyword.
+	DefaultType esMapping.FieldType
+	// Hstore accesses key with hstore operator rather than jsonb operator in PostgreSQL.
+	Hstore bool
+}
+
+// splitMapField splits field into map column and key, it returns false if field isn't key of any map column.
+// the longest prefix is chosen if prefixes are overlapped, e.g. labels and labels.sub
+func (c *SqlConvertor) splitMapField(field string) (string, string, bool) {
+	var column string
+	for prefix := range c.mapFields {
+		if strings.HasPrefix(field, prefix+".") && len(field) > len(prefix)+1 && len(prefix) > len(column) {
+			column = prefix
+		}
+	}
+	if column == "" {
+		return "", "", false
+	}
+	return column, field[len(column)+1:], true
+}
+
+// mapKeyProperty returns the property of map key, key declared in schema has precedence over default type.
+func (c *SqlConvertor) mapKeyProperty(field string, mapField *MapField) *esMapping.Property {
+	if c.mappings != nil {
+		if typMap, err := c.mappings.GetProperty(field); err == nil && typMap[field] != nil {
+			return typMap[field]
+		}
+	}
+	if mapField.DefaultType != esMapping.UNKNOWN_FIELD_TYPE {
+		return &esMapping.Property{Type: mapField.DefaultType}
+	}
+	return &esMapping.Property{Type: esMapping.KEYWORD_FIELD_TYPE}
+}
+
+// jsonPath returns quoted json path of key, e.g. '$."env"'
+func jsonPath(key string) string {
+	key = strings.ReplaceAll(key, "\\", "\\\\")
+	key = strings.ReplaceAll(key, "\"", "\\\"")
+	return quoteString(fmt.Sprintf("$.\"%s\"", key))
+}
+
+// mapValueToSql converts key of map column to the expression of value.
+func (c *SqlConvertor) mapValueToSql(
+	column, key string, mapField *MapField, tType *esMapping.Property,
+) string {
+	number := esMapping.CheckNumberType(tType.Type)
+	switch c.sqlStyle {
+	case ClickHouse:
+		if number {
+			return fmt.Sprintf("toFloat64OrNull(%s[%s])", column, quoteString(key))
+		}
+		return fmt.Sprintf("%s[%s]", column, quoteString(key))
+	case PostgreSQL:
+		expr := fmt.Sprintf("%s ->> %s", column, quoteString(key))
+		if mapField.Hstore {
+			expr = fmt.Sprintf("%s -> %s", column, quoteString(key))
+		}
+		if number {
+			return fmt.Sprintf("CAST(%s AS NUMERIC)", expr)
+		}
+		return expr
+	case MySQL:
+		return fmt.Sprintf("%s ->> %s", column, jsonPath(key))
+	case SQLite:
+		return fmt.Sprintf("json_extract(%s, %s)", column, jsonPath(key))
+	case SQLServer:
+		if number {
+			return fmt.Sprintf("TRY_CAST(JSON_VALUE(%s, %s) AS FLOAT)", column, jsonPath(key))
+		}
+		return fmt.Sprintf("JSON_VALUE(%s, %s)", column, jsonPath(key))
+	default:
+		// sql99 and oracle
+		if number {
+			return fmt.Sprintf("JSON_VALUE(%s, %s RETURNING NUMBER)", column, jsonPath(key))
+		}
+		return fmt.Sprintf("JSON_VALUE(%s, %s)", column, jsonPath(key))
+	}
+}
+
+// mapContainsToSql converts existence of map key to sql.
+func (c *SqlConvertor) mapContainsToSql(column, key string, mapField *MapField) string {
+	switch c.sqlStyle {
+	case ClickHouse:
+		return fmt.Sprintf("mapContains(%s, %s)", column, quoteString(key))
+	case PostgreSQL:
+		// both of jsonb and hstore support operator ?
+		return fmt.Sprintf("%s ? %s", column, quoteString(key))
+	case MySQL:
+		return fmt.Sprintf("JSON_CONTAINS_PATH(%s, 'one', %s)", column, jsonPath(key))
+	case SQLite:
+		return fmt.Sprintf("json_type(%s, %s) IS NOT NULL", column, jsonPath(key))
+	case SQLServer:
+		// JSON_PATH_EXISTS of SQL Server 2022
+		return fmt.Sprintf("JSON_PATH_EXISTS(%s, %s) = 1", column, jsonPath(key))
+	default:
+		// sql99 and oracle
+		return fmt.Sprintf("JSON_EXISTS(%s, %s)", column, jsonPath(key))
+	}
+}
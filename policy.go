@@ -0,0 +1,85 @@
+package lucene_to_sql
+
+import (
+	"fmt"
+	"strings"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+const (
+	// falsePredicate is the predicate which matches nothing
+	falsePredicate = "1 = 0"
+	// truePredicate is the predicate which matches everything
+	truePredicate = "1 = 1"
+)
+
+// DENY_MODE is what to do with the clause on denied field.
+type DENY_MODE int32
+
+const (
+	DenyError DENY_MODE = iota // fail conversion with FieldDeniedError
+	DenyFalse                  // replace clause with predicate matches nothing
+)
+
+// FieldPolicy decides which fields can be queried, field is matched with exact name or pattern (e.g. user.*)
+// case insensitively, because column names of most sql styles are case insensitive.
+type FieldPolicy struct {
+	// Allow lists fields can be queried, empty means all fields are allowed.
+	Allow []string
+	// Deny lists fields can't be queried, it has precedence over Allow.
+	Deny []string
+	Mode DENY_MODE
+}
+
+// Allowed checks whether field can be queried.
+func (p *FieldPolicy) Allowed(field string) bool {
+	if p == nil {
+		return true
+	}
+	if matchAnyField(field, p.Deny) {
+		return false
+	}
+	return len(p.Allow) == 0 || matchAnyField(field, p.Allow)
+}
+
+func matchAnyField(field string, patterns []string) bool {
+	field = strings.ToLower(field)
+	for _, pattern := range patterns {
+		if esMapping.WildcardMatch([]rune(field), []rune(strings.ToLower(pattern))) {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldDeniedError is returned if query has clause on the field denied by FieldPolicy.
+type FieldDeniedError struct {
+	Clause
+}
+
+func (e *FieldDeniedError) Error() string {
+	return fmt.Sprintf("field: %s is not allowed to be queried", e.Field)
+}
+
+// checkFieldPolicy returns true if field is denied, error is returned in DenyError mode.
+func (c *SqlConvertor) checkFieldPolicy(field string) (bool, error) {
+	if c.fieldPolicy.Allowed(field) {
+		return false, nil
+	}
+	if c.fieldPolicy.Mode == DenyError {
+		return true, &FieldDeniedError{Clause: Clause{Field: field}}
+	}
+	return true, nil
+}
+
+// allowedFields removes denied fields, which are expanded from default fields or field pattern.
+func (c *SqlConvertor) allowedFields(fields []string) []string {
+	var res []string
+	for _, field := range fields {
+		if c.fieldPolicy.Allowed(field) {
+			res = append(res, field)
+		}
+	}
+	return res
+}
@@ -0,0 +1,127 @@
+package lucene_to_sql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestFieldPolicy(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"name": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+				"ssn": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+				"internal_score": {
+					Type: esMapping.INTEGER_FIELD_TYPE,
+				},
+			},
+		})),
+		WithDefaultFields("*"),
+	)
+	deny := &FieldPolicy{Deny: []string{"ssn", "internal_*"}}
+	tests := []struct {
+		name      string
+		policy    *FieldPolicy
+		query     string
+		wantSQL   string
+		wantField string
+	}{
+		{
+			name:    "test allowed field",
+			policy:  deny,
+			query:   "name:foo",
+			wantSQL: "name = 'foo'",
+		},
+		{
+			name:      "test denied field",
+			policy:    deny,
+			query:     "name:foo AND ssn:123",
+			wantField: "ssn",
+		},
+		{
+			name:      "test denied field pattern",
+			policy:    deny,
+			query:     "internal_score:[1 TO 2]",
+			wantField: "internal_score",
+		},
+		{
+			name:      "test denied exists query",
+			policy:    deny,
+			query:     "_exists_:ssn",
+			wantField: "ssn",
+		},
+		{
+			name:      "test denied term group",
+			policy:    deny,
+			query:     "ssn:(1 OR 2)",
+			wantField: "ssn",
+		},
+		{
+			name:    "test denied field replaced by false",
+			policy:  &FieldPolicy{Deny: []string{"ssn"}, Mode: DenyFalse},
+			query:   "name:foo OR ssn:123",
+			wantSQL: "name = 'foo' OR 1 = 0",
+		},
+		{
+			name:      "test allow list",
+			policy:    &FieldPolicy{Allow: []string{"name"}},
+			query:     "internal_score:1",
+			wantField: "internal_score",
+		},
+		{
+			name:    "test default fields skip denied fields",
+			policy:  deny,
+			query:   "foo",
+			wantSQL: "name = 'foo'",
+		},
+		{
+			name:    "test field pattern skip denied fields",
+			policy:  &FieldPolicy{Deny: []string{"ssn"}},
+			query:   "*:foo",
+			wantSQL: "name = 'foo'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cvt.LuceneToSql(tt.query, WithFieldPolicy(tt.policy))
+			if tt.wantField != "" {
+				var deniedErr *FieldDeniedError
+				assert.True(t, errors.As(err, &deniedErr), err)
+				assert.Equal(t, tt.wantField, deniedErr.Field)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantSQL, got)
+			}
+		})
+	}
+
+	// policy of single call isn't kept in convertor
+	got, err := cvt.LuceneToSql("ssn:123")
+	assert.NoError(t, err)
+	assert.Equal(t, "ssn = '123'", got)
+
+	// case of field can't bypass policy, e.g. unknown field of default type
+	cvt = NewSqlConvertor(
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"name": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+			},
+		})),
+		WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldDefaultType}),
+		WithFieldPolicy(&FieldPolicy{Deny: []string{"ssn", "Internal_*"}}),
+	)
+	for _, query := range []string{"SSN:1", "Ssn:1", "internal_score:1", "INTERNAL_SCORE:1"} {
+		_, err = cvt.LuceneToSql(query)
+		var deniedErr *FieldDeniedError
+		assert.True(t, errors.As(err, &deniedErr), query)
+	}
+}
@@ -0,0 +1,60 @@
+package lucene_to_sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// wildcardPrefix returns prefix of wildcard term which only has trailing *, e.g. abc*
+func wildcardPrefix(tks []string) (string, bool) {
+	if len(tks) < 2 || tks[len(tks)-1] != "*" {
+		return "", false
+	}
+	var prefix strings.Builder
+	for _, tk := range tks[:len(tks)-1] {
+		switch {
+		case tk == "*" || tk == "?":
+			return "", false
+		case strings.HasPrefix(tk, "\\"):
+			prefix.WriteString(tk[1:])
+		default:
+			prefix.WriteString(tk)
+		}
+	}
+	return prefix.String(), true
+}
+
+// prefixSuccessor returns the least string which is greater than all strings having prefix,
+// it returns false if there is no such string, e.g. prefix only consists of max rune.
+func prefixSuccessor(prefix string) (string, bool) {
+	runes := []rune(prefix)
+	for i := len(runes) - 1; i >= 0; i-- {
+		r := runes[i] + 1
+		if r > utf8.MaxRune {
+			// carry to previous rune
+			continue
+		}
+		if r >= 0xD800 && r <= 0xDFFF {
+			// surrogate halves aren't valid in utf-8
+			r = 0xE000
+		}
+		runes[i] = r
+		return string(runes[:i+1]), true
+	}
+	return "", false
+}
+
+// prefixQueryToSql converts prefix query to range which can be used by index,
+// range is compared in binary order of utf-8, so column should use binary collation.
+func (c *SqlConvertor) prefixQueryToSql(field, prefix string) string {
+	if c.sqlStyle == ClickHouse {
+		return fmt.Sprintf("startsWith(%s, %s)", field, quoteString(prefix))
+	}
+	sql := NewSQL()
+	sql.AddAndClause(fmt.Sprintf("%s >= %s", field, quoteString(prefix)), false, false)
+	if upper, ok := prefixSuccessor(prefix); ok {
+		sql.AddAndClause(fmt.Sprintf("%s < %s", field, quoteString(upper)), true, false)
+	}
+	return sql.String()
+}
@@ -0,0 +1,97 @@
+package lucene_to_sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixSuccessor(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "test ascii",
+			prefix: "abc",
+			want:   "abd",
+			wantOk: true,
+		},
+		{
+			name:   "test multi bytes rune",
+			prefix: "中文",
+			want:   "中斈",
+			wantOk: true,
+		},
+		{
+			name:   "test skip surrogate",
+			prefix: "a\uD7FF",
+			want:   "a\uE000",
+			wantOk: true,
+		},
+		{
+			name:   "test carry max rune",
+			prefix: "a\U0010FFFF",
+			want:   "b",
+			wantOk: true,
+		},
+		{
+			name:   "test only max rune",
+			prefix: "\U0010FFFF\U0010FFFF",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := prefixSuccessor(tt.prefix)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWildcardPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		tks    []string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "test prefix",
+			tks:    []string{"ab", "c", "*"},
+			want:   "abc",
+			wantOk: true,
+		},
+		{
+			name:   "test escaped prefix",
+			tks:    []string{"a", "\\:", "*"},
+			want:   "a:",
+			wantOk: true,
+		},
+		{
+			name:   "test leading wildcard",
+			tks:    []string{"*", "abc", "*"},
+			wantOk: false,
+		},
+		{
+			name:   "test question mark",
+			tks:    []string{"a", "?", "*"},
+			wantOk: false,
+		},
+		{
+			name:   "test suffix",
+			tks:    []string{"*", "abc"},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := wildcardPrefix(tt.tks)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package lucene_to_sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+	"github.com/zhuliquan/lucene_parser/term"
+)
+
+// maxProximityAlternatives limits number of alternatives of tsquery / regexp, which are enumerated by
+// orders and distances of words.
+const maxProximityAlternatives = 256
+
+// proximityQueryToSql converts proximity phrase (e.g. "foo bar"~3) on text field.
+// words are matched in any order like NEAR of full text search, and at most slop other words are between
+// the first and the last word, so that query matches same rows in all sql styles. it's a bit looser than
+// lucene, which costs 2 moves to swap two words.
+func (c *SqlConvertor) proximityQueryToSql(
+	field string, tType *esMapping.Property, value *term.Term,
+) (string, error) {
+	phrase := &term.Term{FuzzyTerm: &term.FuzzyTerm{PhraseTerm: value.FuzzyTerm.PhraseTerm}}
+	// "foo bar"~ is same as slop 0 in lucene
+	slop := int(value.FuzzyTerm.Fuzzy().Float())
+	if !esMapping.CheckTextType(tType.Type) || slop <= 0 {
+		// slop is meaningless for keyword and exact phrase
+		return c.phraseQueryToSql(field, tType, phrase)
+	}
+
+	val := strings.Trim(value.FuzzyTerm.PhraseTerm.String(), "\"")
+	var words []string
+	if tokenizer, ok := c.tokenizers[field]; ok {
+		words = tokenizer.Split(val)
+	} else {
+		words = splitWords(val)
+	}
+	if len(words) < 2 {
+		return c.phraseQueryToSql(field, tType, phrase)
+	}
+
+	switch c.sqlStyle {
+	case PostgreSQL:
+		return fmt.Sprintf("to_tsvector(%s) @@ to_tsquery(%s)",
+			field, quoteString(c.tsqueryProximity(words, slop))), nil
+	case SQLite:
+		// fts5 virtual table
+		return fmt.Sprintf("%s MATCH %s", field,
+			quoteString(fmt.Sprintf("NEAR(%s, %d)", strings.Join(quoteWords(words), " "), slop))), nil
+	case MySQL:
+		// distance of mysql is measured from the first word to the last word
+		return fmt.Sprintf("MATCH (%s) AGAINST (%s IN BOOLEAN MODE)", field,
+			quoteString(fmt.Sprintf("\"%s\" @%d", strings.Join(words, " "), slop+len(words)-1))), nil
+	default:
+		return c.regexpProximityToSql(field, words, slop), nil
+	}
+}
+
+// pairSlop is the slop of every two words, which are within the distance of the first and the last word.
+func pairSlop(words []string, slop int) int {
+	return slop + len(words) - 2
+}
+
+// tsqueryProximity returns tsquery which matches words within slop. if there are too many alternatives of
+// orders and distances of words, it's approximated by proximity of every two words, or all of words if
+// alternatives of two words are still too many.
+func (c *SqlConvertor) tsqueryProximity(words []string, slop int) string {
+	if alts := tsqueryAlternatives(words, slop); len(alts) <= maxProximityAlternatives {
+		return strings.Join(alts, " | ")
+	}
+	if len(tsqueryAlternatives(words[:2], pairSlop(words, slop))) <= maxProximityAlternatives {
+		c.warn(ProximityApproximated, "proximity of %d words with slop %d is approximated by every two words",
+			len(words), slop)
+		var pairs []string
+		for i := range words {
+			for j := i + 1; j < len(words); j++ {
+				alts := tsqueryAlternatives([]string{words[i], words[j]}, pairSlop(words, slop))
+				pairs = append(pairs, "( "+strings.Join(alts, " | ")+" )")
+			}
+		}
+		return strings.Join(pairs, " & ")
+	}
+	c.warn(ProximityApproximated, "proximity of %d words with slop %d is approximated by all of words", len(words), slop)
+	lexemes := make([]string, 0, len(words))
+	for _, word := range words {
+		lexemes = append(lexemes, tsqueryLexeme(word))
+	}
+	return strings.Join(lexemes, " & ")
+}
+
+// regexpProximityToSql returns regexp matching of words within slop. if there are too many alternatives
+// of orders and distances of words, it's approximated by proximity of every two words.
+func (c *SqlConvertor) regexpProximityToSql(field string, words []string, slop int) string {
+	if node, ok := proximityRegexp(words, slop); ok {
+		return c.regexpMatchToSql(field, node)
+	}
+	c.warn(ProximityApproximated, "proximity of %d words with slop %d is approximated by every two words",
+		len(words), slop)
+	sql := NewSQL()
+	for i := range words {
+		for j := i + 1; j < len(words); j++ {
+			// two words have only two orders without gap between words
+			node, _ := proximityRegexp([]string{words[i], words[j]}, pairSlop(words, slop))
+			sql.AddAndClause(c.regexpMatchToSql(field, node), i != 0 || j != 1, false)
+		}
+	}
+	return fmt.Sprintf("( %s )", sql.String())
+}
+
+// splitWords splits text into words by chars which are neither letter nor digit.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func quoteWords(words []string) []string {
+	res := make([]string, 0, len(words))
+	for _, word := range words {
+		res = append(res, "\""+strings.ReplaceAll(word, "\"", "\"\"")+"\"")
+	}
+	return res
+}
+
+// permutations returns all orders of words, it stops when there are more than maxProximityAlternatives orders.
+func permutations(words []string) [][]string {
+	if len(words) <= 1 {
+		return [][]string{words}
+	}
+	var res [][]string
+	for i := range words {
+		rest := make([]string, 0, len(words)-1)
+		rest = append(append(rest, words[:i]...), words[i+1:]...)
+		for _, order := range permutations(rest) {
+			res = append(res, append([]string{words[i]}, order...))
+			if len(res) > maxProximityAlternatives {
+				return res
+			}
+		}
+	}
+	return res
+}
+
+// tsqueryLexeme quotes word as lexeme of tsquery, so that operators in word aren't parsed by tsquery.
+func tsqueryLexeme(word string) string {
+	word = strings.ReplaceAll(word, "\\", "\\\\")
+	return "'" + strings.ReplaceAll(word, "'", "''") + "'"
+}
+
+// tsqueryAlternatives enumerates alternatives of tsquery, words are in any order and distances of words
+// sum up to slop, for instance "a b"~1 => 'a' <1> 'b' | 'a' <2> 'b' | 'b' <1> 'a' | 'b' <2> 'a'.
+// it stops when there are more than maxProximityAlternatives alternatives.
+func tsqueryAlternatives(words []string, slop int) []string {
+	var alts []string
+	var walk func(order []string, i, rest int, prefix string)
+	walk = func(order []string, i, rest int, prefix string) {
+		if i == len(order) {
+			alts = append(alts, prefix)
+			return
+		}
+		for d := 0; d <= rest && len(alts) <= maxProximityAlternatives; d++ {
+			walk(order, i+1, rest-d, fmt.Sprintf("%s <%d> %s", prefix, d+1, tsqueryLexeme(order[i])))
+		}
+	}
+	for _, order := range permutations(words) {
+		if len(alts) > maxProximityAlternatives {
+			break
+		}
+		walk(order, 1, slop, tsqueryLexeme(order[0]))
+	}
+	return alts
+}
+
+// proximityRegexp returns regexp which matches words in any order, at most slop words are between the first
+// and the last word. it returns false if there are more than maxProximityAlternatives alternatives.
+func proximityRegexp(words []string, slop int) (regexpNode, bool) {
+	alnum := &regexpClass{alnum: true}
+	sep := &regexpClass{negate: true, alnum: true}
+	anyString := &regexpRepeat{node: &regexpAnyChar{}, min: 0, max: -1}
+	seps := &regexpRepeat{node: sep, min: 1, max: -1}
+	gap := &regexpConcat{items: []regexpNode{seps, &regexpRepeat{node: alnum, min: 1, max: -1}}}
+	word := func(w string) regexpNode {
+		chars := make([]regexpNode, 0, len(w))
+		for _, r := range w {
+			chars = append(chars, &regexpChar{r: r})
+		}
+		return &regexpConcat{items: chars}
+	}
+
+	// number of words in every gap is enumerated, except the last gap which takes the rest of slop
+	var alts []regexpNode
+	var walk func(order []string, i, rest int, items []regexpNode)
+	walk = func(order []string, i, rest int, items []regexpNode) {
+		if i == len(order)-1 {
+			if rest > 0 {
+				items = append(items, &regexpRepeat{node: gap, min: 0, max: rest})
+			}
+			alts = append(alts, &regexpConcat{items: append(items, seps, word(order[i]))})
+			return
+		}
+		for d := 0; d <= rest && len(alts) <= maxProximityAlternatives; d++ {
+			next := append([]regexpNode(nil), items...)
+			if d > 0 {
+				next = append(next, &regexpRepeat{node: gap, min: d, max: d})
+			}
+			walk(order, i+1, rest-d, append(next, seps, word(order[i])))
+		}
+	}
+	for _, order := range permutations(words) {
+		if len(alts) > maxProximityAlternatives {
+			return nil, false
+		}
+		walk(order, 1, slop, []regexpNode{word(order[0])})
+	}
+	if len(alts) > maxProximityAlternatives {
+		return nil, false
+	}
+	return &regexpConcat{items: []regexpNode{
+		&regexpRepeat{node: &regexpConcat{items: []regexpNode{anyString, sep}}, min: 0, max: 1},
+		&regexpUnion{alts: alts},
+		&regexpRepeat{node: &regexpConcat{items: []regexpNode{sep, anyString}}, min: 0, max: 1},
+	}}, true
+}
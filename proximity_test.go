@@ -0,0 +1,108 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestProximityRegexp(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		slop  int
+		text  string
+		want  bool
+	}{
+		{name: "test words in order", words: []string{"a", "b", "c"}, slop: 1, text: "x a b y c", want: true},
+		{name: "test words in any order", words: []string{"a", "b", "c"}, slop: 1, text: "c, b a", want: true},
+		{name: "test total gap is limited", words: []string{"a", "b", "c"}, slop: 1, text: "a x b y c", want: false},
+		{name: "test gap of two words", words: []string{"a", "b"}, slop: 2, text: "b x y a", want: true},
+		{name: "test gap exceeds slop", words: []string{"a", "b"}, slop: 2, text: "a x y z b", want: false},
+		{name: "test word is part of other word", words: []string{"a", "b"}, slop: 1, text: "ab b", want: false},
+		{name: "test unicode words", words: []string{"über", "straße"}, slop: 1, text: "straße, groß über", want: true},
+		{name: "test unicode word is part of other word", words: []string{"über", "straße"}, slop: 1, text: "éüber straße", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, ok := proximityRegexp(tt.words, tt.slop)
+			assert.True(t, ok)
+			re := regexp.MustCompile(renderRegexp(node, re2Flavor))
+			assert.Equal(t, tt.want, re.MatchString(tt.text))
+		})
+	}
+
+	_, ok := proximityRegexp([]string{"a", "b", "c", "d", "e", "f"}, 1)
+	assert.False(t, ok)
+}
+
+func TestProximityApproximated(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"title": {
+				Type: esMapping.TEXT_FIELD_TYPE,
+			},
+		},
+	}))
+	tests := []struct {
+		name    string
+		style   SQL_STYLE
+		query   string
+		wantSQL string
+		warning string
+	}{
+		{
+			name:    "test few alternatives are exact",
+			style:   PostgreSQL,
+			query:   `title:"a b"~1`,
+			wantSQL: `to_tsvector(title) @@ to_tsquery('''a'' <1> ''b'' | ''a'' <2> ''b'' | ''b'' <1> ''a'' | ''b'' <2> ''a''')`,
+		},
+		{
+			name:    "test every two words of tsquery",
+			style:   PostgreSQL,
+			query:   `title:"a b c d e"~2`,
+			warning: "proximity of 5 words with slop 2 is approximated by every two words",
+		},
+		{
+			name:    "test all of words of tsquery",
+			style:   PostgreSQL,
+			query:   `title:"a b"~200`,
+			wantSQL: `to_tsvector(title) @@ to_tsquery('''a'' & ''b''')`,
+			warning: "proximity of 2 words with slop 200 is approximated by all of words",
+		},
+		{
+			name:    "test every two words of regexp",
+			style:   ClickHouse,
+			query:   `title:"a b c d e f"~1`,
+			warning: "proximity of 6 words with slop 1 is approximated by every two words",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewSqlConvertor(schema, WithSQLStyle(tt.style)).Convert(context.Background(), tt.query)
+			assert.NoError(t, err)
+			if tt.wantSQL != "" {
+				assert.Equal(t, tt.wantSQL, res.SQL)
+			}
+			if tt.warning == "" {
+				assert.Empty(t, res.Warnings)
+			} else {
+				assert.Equal(t, []*Warning{{
+					Kind:    ProximityApproximated,
+					Clause:  Clause{Field: "title", Term: tt.query[len("title:"):], Offset: 0},
+					Message: tt.warning,
+				}}, res.Warnings)
+			}
+		})
+	}
+
+	// every two words are within the distance of the first and the last word
+	node, ok := proximityRegexp([]string{"a", "f"}, pairSlop([]string{"a", "b", "c", "d", "e", "f"}, 1))
+	assert.True(t, ok)
+	re := regexp.MustCompile(renderRegexp(node, re2Flavor))
+	assert.True(t, re.MatchString("f b x c d e a"))
+	assert.False(t, re.MatchString("f b x c y d e a"))
+}
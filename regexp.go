@@ -0,0 +1,717 @@
+package lucene_to_sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// regexpFlavor is the regular expression syntax which lucene regexp is translated to.
+type regexpFlavor int
+
+const (
+	// extended regexp supports backslash escape in bracket expression,
+	// e.g. ARE of PostgreSQL, ICU of MySQL and regexp extension of SQLite
+	extendedFlavor regexpFlavor = iota
+	// RE2 is extended regexp whose posix classes are ascii only, e.g. ClickHouse and SQL Server
+	re2Flavor
+	// posix regexp treats backslash in bracket expression as literal, e.g. Oracle
+	posixFlavor
+	// SIMILAR TO of sql99, which matches whole string and uses sql wildcards
+	similarFlavor
+)
+
+// regexpNode is node of lucene regexp syntax tree.
+type regexpNode interface {
+	render(b *strings.Builder, flavor regexpFlavor)
+}
+
+type (
+	// regexpUnion is a|b
+	regexpUnion struct {
+		alts []regexpNode
+	}
+	// regexpConcat is ab
+	regexpConcat struct {
+		items []regexpNode
+	}
+	// regexpRepeat is a*, a+, a?, a{n,m}, max is -1 if it's unbounded
+	regexpRepeat struct {
+		node     regexpNode
+		min, max int
+	}
+	// regexpChar is literal char
+	regexpChar struct {
+		r rune
+	}
+	// regexpAnyChar is .
+	regexpAnyChar struct{}
+	// regexpClass is [a-z] or [^a-z]
+	regexpClass struct {
+		negate bool
+		ranges [][2]rune
+		alnum  bool // letters and digits of unicode, e.g. [[:alnum:]]
+	}
+)
+
+func parseRegexp(pattern string) (regexpNode, error) {
+	p := &regexpParser{pattern: []rune(pattern)}
+	node, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	if p.more() {
+		return nil, p.errorf("unexpected '%c'", p.peek())
+	}
+	return node, nil
+}
+
+// renderRegexp renders node as regexp of flavor, lucene regexp is anchored which matches whole string.
+func renderRegexp(node regexpNode, flavor regexpFlavor) string {
+	var b strings.Builder
+	if flavor == similarFlavor {
+		node.render(&b, flavor)
+		return b.String()
+	}
+	b.WriteString("^")
+	if _, ok := node.(*regexpUnion); ok {
+		b.WriteString("(")
+		node.render(&b, flavor)
+		b.WriteString(")")
+	} else {
+		node.render(&b, flavor)
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// regexpMatchToSql converts matching of regexp node to sql of sql style.
+func (c *SqlConvertor) regexpMatchToSql(field string, node regexpNode) string {
+	flavor := extendedFlavor
+	switch c.sqlStyle {
+	case ClickHouse, SQLServer:
+		flavor = re2Flavor
+	case Oracle:
+		flavor = posixFlavor
+	case Standard:
+		flavor = similarFlavor
+	}
+	ci := c.caseInsensitiveRegexp
+	if ci && (c.sqlStyle == SQLite || c.sqlStyle == Standard) {
+		// neither REGEXP of sqlite nor SIMILAR TO has case insensitive flag
+		field, node = fmt.Sprintf("LOWER(%s)", field), lowerRegexp(node)
+	}
+	pattern := renderRegexp(node, flavor)
+	if ci && c.sqlStyle == ClickHouse {
+		pattern = "(?i)" + pattern
+	}
+	if c.sqlStyle == MySQL || c.sqlStyle == ClickHouse {
+		// backslash is escape char of string literal
+		pattern = strings.ReplaceAll(pattern, "\\", "\\\\")
+	}
+	pattern = quoteString(pattern)
+	switch c.sqlStyle {
+	case SQLite:
+		return fmt.Sprintf("%s REGEXP %s", field, pattern)
+	case MySQL:
+		if ci {
+			return fmt.Sprintf("REGEXP_LIKE(%s, %s, 'i')", field, pattern)
+		}
+		return fmt.Sprintf("%s REGEXP %s", field, pattern)
+	case Oracle:
+		if ci {
+			return fmt.Sprintf("regexp_like(%s, %s, 'i')", field, pattern)
+		}
+		return fmt.Sprintf("regexp_like(%s, %s)", field, pattern)
+	case SQLServer:
+		// REGEXP_LIKE of SQL Server 2025
+		if ci {
+			return fmt.Sprintf("REGEXP_LIKE(%s, %s, 'i')", field, pattern)
+		}
+		return fmt.Sprintf("REGEXP_LIKE(%s, %s)", field, pattern)
+	case ClickHouse:
+		return fmt.Sprintf("match(%s, %s)", field, pattern)
+	case PostgreSQL:
+		if ci {
+			return fmt.Sprintf("%s ~* %s", field, pattern)
+		}
+		return fmt.Sprintf("%s ~ %s", field, pattern)
+	default:
+		// sql99, which doesn't have default escape char
+		return fmt.Sprintf("%s SIMILAR TO %s ESCAPE '\\'", field, pattern)
+	}
+}
+
+// lowerRegexp returns copy of node which matches lower case of the strings matched by node case insensitively,
+// only literal chars and ranges of class are changed, e.g. A[B-D]\W => a[B-Db-d][^a-zA-Z0-9_]
+func lowerRegexp(node regexpNode) regexpNode {
+	switch n := node.(type) {
+	case *regexpUnion:
+		alts := make([]regexpNode, 0, len(n.alts))
+		for _, alt := range n.alts {
+			alts = append(alts, lowerRegexp(alt))
+		}
+		return &regexpUnion{alts: alts}
+	case *regexpConcat:
+		items := make([]regexpNode, 0, len(n.items))
+		for _, item := range n.items {
+			items = append(items, lowerRegexp(item))
+		}
+		return &regexpConcat{items: items}
+	case *regexpRepeat:
+		return &regexpRepeat{node: lowerRegexp(n.node), min: n.min, max: n.max}
+	case *regexpChar:
+		return &regexpChar{r: unicode.ToLower(n.r)}
+	case *regexpClass:
+		// upper case chars of class are kept, so that negated class excludes both cases
+		class := &regexpClass{negate: n.negate, ranges: append([][2]rune(nil), n.ranges...), alnum: n.alnum}
+		for _, rg := range n.ranges {
+			lower := [2]rune{unicode.ToLower(rg[0]), unicode.ToLower(rg[1])}
+			if rg[0] != rg[1] {
+				// upper case letters in range, e.g. 0-Z => A-Z
+				lo, hi := rg[0], rg[1]
+				if lo < 'A' {
+					lo = 'A'
+				}
+				if hi > 'Z' {
+					hi = 'Z'
+				}
+				if lo > hi {
+					continue
+				}
+				lower = [2]rune{lo + 'a' - 'A', hi + 'a' - 'A'}
+			}
+			if !class.contains(lower) {
+				class.ranges = append(class.ranges, lower)
+			}
+		}
+		return class
+	default:
+		return node
+	}
+}
+
+// contains checks whether range is in one of ranges of class.
+func (n *regexpClass) contains(rg [2]rune) bool {
+	for _, r := range n.ranges {
+		if r[0] <= rg[0] && rg[1] <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// regexpParser parses lucene regexp, see org.apache.lucene.util.automaton.RegExp
+//
+//	union    ::= concat ( '|' concat )*
+//	concat   ::= repeat*
+//	repeat   ::= simple ( '?' | '*' | '+' | '{' n ( ',' m? )? '}' )*
+//	simple   ::= char | '.' | '@' | '[' class ']' | '"' string '"' | '(' union? ')' | '<' n '-' m '>'
+type regexpParser struct {
+	pattern []rune
+	pos     int
+}
+
+func (p *regexpParser) more() bool {
+	return p.pos < len(p.pattern)
+}
+
+func (p *regexpParser) peek() rune {
+	return p.pattern[p.pos]
+}
+
+func (p *regexpParser) next() rune {
+	r := p.pattern[p.pos]
+	p.pos++
+	return r
+}
+
+func (p *regexpParser) match(r rune) bool {
+	if p.more() && p.peek() == r {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *regexpParser) errorf(format string, args ...interface{}) error {
+	return &RegexpSyntaxError{Pattern: string(p.pattern), Position: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *regexpParser) parseUnion() (regexpNode, error) {
+	var alts []regexpNode
+	for {
+		node, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, node)
+		if !p.match('|') {
+			break
+		}
+	}
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+	return &regexpUnion{alts: alts}, nil
+}
+
+func (p *regexpParser) parseConcat() (regexpNode, error) {
+	var items []regexpNode
+	for p.more() && p.peek() != '|' && p.peek() != ')' {
+		node, err := p.parseRepeat()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, node)
+	}
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	return &regexpConcat{items: items}, nil
+}
+
+func (p *regexpParser) parseRepeat() (regexpNode, error) {
+	node, err := p.parseSimple()
+	if err != nil {
+		return nil, err
+	}
+	for p.more() {
+		switch p.peek() {
+		case '?':
+			node = &regexpRepeat{node: node, min: 0, max: 1}
+		case '*':
+			node = &regexpRepeat{node: node, min: 0, max: -1}
+		case '+':
+			node = &regexpRepeat{node: node, min: 1, max: -1}
+		case '{':
+			p.next()
+			min, err := p.parseNumber()
+			if err != nil {
+				return nil, err
+			}
+			max := min
+			if p.match(',') {
+				max = -1
+				if p.more() && p.peek() != '}' {
+					if max, err = p.parseNumber(); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if !p.match('}') {
+				return nil, p.errorf("expected '}'")
+			}
+			if max != -1 && max < min {
+				return nil, p.errorf("invalid repetition {%d,%d}", min, max)
+			}
+			node = &regexpRepeat{node: node, min: min, max: max}
+			continue
+		default:
+			return node, nil
+		}
+		p.next()
+	}
+	return node, nil
+}
+
+func (p *regexpParser) parseNumber() (int, error) {
+	start := p.pos
+	for p.more() && p.peek() >= '0' && p.peek() <= '9' {
+		p.next()
+	}
+	if start == p.pos {
+		return 0, p.errorf("expected integer")
+	}
+	return strconv.Atoi(string(p.pattern[start:p.pos]))
+}
+
+func (p *regexpParser) parseSimple() (regexpNode, error) {
+	switch r := p.next(); r {
+	case '.':
+		return &regexpAnyChar{}, nil
+	case '@':
+		return &regexpRepeat{node: &regexpAnyChar{}, min: 0, max: -1}, nil
+	case '&', '~', '#':
+		// intersection, complement and empty language can't be expressed by regexp of sql
+		p.pos--
+		return nil, p.errorf("operator '%c' is not supported", r)
+	case '*', '+', '?', '{':
+		p.pos--
+		return nil, p.errorf("nothing to repeat before '%c'", r)
+	case '[':
+		return p.parseClass()
+	case '"':
+		var items []regexpNode
+		for p.more() && p.peek() != '"' {
+			items = append(items, &regexpChar{r: p.next()})
+		}
+		if !p.match('"') {
+			return nil, p.errorf("expected '\"'")
+		}
+		return &regexpConcat{items: items}, nil
+	case '(':
+		if p.match(')') {
+			return &regexpConcat{}, nil
+		}
+		node, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		if !p.match(')') {
+			return nil, p.errorf("expected ')'")
+		}
+		return node, nil
+	case '<':
+		return p.parseInterval()
+	case '\\':
+		return p.parseEscape(false)
+	default:
+		return &regexpChar{r: r}, nil
+	}
+}
+
+// parseEscape parses char after backslash, shorthand classes \d, \s and \w are supported.
+func (p *regexpParser) parseEscape(inClass bool) (regexpNode, error) {
+	if !p.more() {
+		return nil, p.errorf("trailing backslash")
+	}
+	r := p.next()
+	var class *regexpClass
+	switch r {
+	case 'd', 'D':
+		class = &regexpClass{ranges: [][2]rune{{'0', '9'}}}
+	case 's', 'S':
+		class = &regexpClass{ranges: [][2]rune{{' ', ' '}, {'\t', '\n'}, {'\v', '\r'}}}
+	case 'w', 'W':
+		class = &regexpClass{ranges: [][2]rune{{'a', 'z'}, {'A', 'Z'}, {'0', '9'}, {'_', '_'}}}
+	default:
+		return &regexpChar{r: r}, nil
+	}
+	if r >= 'A' && r <= 'Z' {
+		if inClass {
+			p.pos -= 2
+			return nil, p.errorf("negated shorthand '\\%c' in character class is not supported", r)
+		}
+		class.negate = true
+	}
+	return class, nil
+}
+
+func (p *regexpParser) parseClass() (regexpNode, error) {
+	class := &regexpClass{negate: p.match('^')}
+	for p.more() && p.peek() != ']' {
+		lo, err := p.parseClassChar()
+		if err != nil {
+			return nil, err
+		}
+		if shorthand, ok := lo.(*regexpClass); ok {
+			class.ranges = append(class.ranges, shorthand.ranges...)
+			continue
+		}
+		from := lo.(*regexpChar).r
+		to := from
+		if p.more() && p.peek() == '-' && p.pos+1 < len(p.pattern) && p.pattern[p.pos+1] != ']' {
+			p.next()
+			hi, err := p.parseClassChar()
+			if err != nil {
+				return nil, err
+			}
+			c, ok := hi.(*regexpChar)
+			if !ok {
+				return nil, p.errorf("invalid character range")
+			}
+			if to = c.r; to < from {
+				return nil, p.errorf("invalid character range %c-%c", from, to)
+			}
+		}
+		class.ranges = append(class.ranges, [2]rune{from, to})
+	}
+	if !p.match(']') {
+		return nil, p.errorf("expected ']'")
+	}
+	if len(class.ranges) == 0 {
+		return nil, p.errorf("empty character class")
+	}
+	return class, nil
+}
+
+func (p *regexpParser) parseClassChar() (regexpNode, error) {
+	r := p.next()
+	if r == '\\' {
+		return p.parseEscape(true)
+	}
+	return &regexpChar{r: r}, nil
+}
+
+// parseInterval parses numeric interval <n-m>, named automaton <name> isn't supported.
+func (p *regexpParser) parseInterval() (regexpNode, error) {
+	start := p.pos
+	for p.more() && p.peek() != '>' {
+		p.next()
+	}
+	if !p.more() {
+		return nil, p.errorf("expected '>'")
+	}
+	body := string(p.pattern[start:p.pos])
+	p.next()
+	i := strings.Index(body, "-")
+	if i <= 0 || i == len(body)-1 || !isDigits(body[:i]) || !isDigits(body[i+1:]) {
+		p.pos = start - 1
+		return nil, p.errorf("named automaton <%s> is not supported", body)
+	}
+	smin, smax := body[:i], body[i+1:]
+	min, err := strconv.ParseInt(smin, 10, 64)
+	if err != nil {
+		return nil, p.errorf("invalid interval <%s>", body)
+	}
+	max, err := strconv.ParseInt(smax, 10, 64)
+	if err != nil {
+		return nil, p.errorf("invalid interval <%s>", body)
+	}
+	if min > max {
+		min, max = max, min
+	}
+	// same as lucene, interval is fixed width if both of bounds have same length (e.g. <01-10>),
+	// otherwise number may have leading zeros.
+	if len(smin) == len(smax) {
+		return intervalToRegexp(min, max, len(smin)), nil
+	}
+	return &regexpConcat{items: []regexpNode{
+		&regexpRepeat{node: &regexpChar{r: '0'}, min: 0, max: -1},
+		intervalToRegexp(min, max, 0),
+	}}, nil
+}
+
+// intervalToRegexp returns regexp matching numbers in [min, max],
+// numbers are padded with zeros to digits if digits is greater than 0.
+func intervalToRegexp(min, max int64, digits int) regexpNode {
+	var alts []regexpNode
+	if digits > 0 {
+		lo := fmt.Sprintf("%0*d", digits, min)
+		hi := fmt.Sprintf("%0*d", digits, max)
+		alts = digitsRange(lo, hi)
+	} else {
+		lo := strconv.FormatInt(min, 10)
+		hi := strconv.FormatInt(max, 10)
+		for n := len(lo); n <= len(hi); n++ {
+			from, to := "1"+strings.Repeat("0", n-1), strings.Repeat("9", n)
+			if n == len(lo) {
+				from = lo
+			}
+			if n == len(hi) {
+				to = hi
+			}
+			alts = append(alts, digitsRange(from, to)...)
+		}
+	}
+	if len(alts) == 1 {
+		return alts[0]
+	}
+	return &regexpUnion{alts: alts}
+}
+
+// digitsRange returns alternatives matching digit strings between lo and hi, which have same length.
+func digitsRange(lo, hi string) []regexpNode {
+	if lo == hi {
+		return []regexpNode{digitsLiteral(lo)}
+	}
+	if lo[0] == hi[0] {
+		return prefixDigit(lo[0], digitsRange(lo[1:], hi[1:]))
+	}
+
+	// e.g. 123-456 => 12[3-9] | 1[3-9][0-9] | [2-3][0-9]{2} | 4[0-4][0-9] | 45[0-6]
+	rest := len(lo) - 1
+	from, to := lo[0], hi[0]
+	var head, tail []regexpNode
+	if strings.Trim(lo[1:], "0") != "" {
+		head = prefixDigit(lo[0], digitsRange(lo[1:], strings.Repeat("9", rest)))
+		from++
+	}
+	if strings.Trim(hi[1:], "9") != "" {
+		tail = prefixDigit(hi[0], digitsRange(strings.Repeat("0", rest), hi[1:]))
+		to--
+	}
+	alts := head
+	if from <= to {
+		var items []regexpNode
+		if from == to {
+			items = append(items, &regexpChar{r: rune(from)})
+		} else {
+			items = append(items, &regexpClass{ranges: [][2]rune{{rune(from), rune(to)}}})
+		}
+		if rest > 0 {
+			items = append(items, &regexpRepeat{
+				node: &regexpClass{ranges: [][2]rune{{'0', '9'}}}, min: rest, max: rest,
+			})
+		}
+		alts = append(alts, &regexpConcat{items: items})
+	}
+	return append(alts, tail...)
+}
+
+func prefixDigit(d byte, alts []regexpNode) []regexpNode {
+	res := make([]regexpNode, 0, len(alts))
+	for _, alt := range alts {
+		res = append(res, &regexpConcat{items: []regexpNode{&regexpChar{r: rune(d)}, alt}})
+	}
+	return res
+}
+
+func digitsLiteral(s string) regexpNode {
+	items := make([]regexpNode, 0, len(s))
+	for _, r := range s {
+		items = append(items, &regexpChar{r: r})
+	}
+	return &regexpConcat{items: items}
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (n *regexpUnion) render(b *strings.Builder, flavor regexpFlavor) {
+	for i, alt := range n.alts {
+		if i != 0 {
+			b.WriteString("|")
+		}
+		alt.render(b, flavor)
+	}
+}
+
+func (n *regexpConcat) render(b *strings.Builder, flavor regexpFlavor) {
+	for _, item := range n.items {
+		if _, ok := item.(*regexpUnion); ok {
+			b.WriteString("(")
+			item.render(b, flavor)
+			b.WriteString(")")
+		} else {
+			item.render(b, flavor)
+		}
+	}
+}
+
+func (n *regexpRepeat) render(b *strings.Builder, flavor regexpFlavor) {
+	if _, ok := n.node.(*regexpAnyChar); ok && flavor == similarFlavor && n.min == 0 && n.max == -1 {
+		b.WriteString("%")
+		return
+	}
+	if isAtom(n.node) {
+		n.node.render(b, flavor)
+	} else {
+		b.WriteString("(")
+		n.node.render(b, flavor)
+		b.WriteString(")")
+	}
+	switch {
+	case n.min == 1 && n.max == 1:
+	case n.min == 0 && n.max == 1:
+		b.WriteString("?")
+	case n.min == 0 && n.max == -1:
+		b.WriteString("*")
+	case n.min == 1 && n.max == -1:
+		b.WriteString("+")
+	case n.max == -1:
+		fmt.Fprintf(b, "{%d,}", n.min)
+	case n.min == n.max:
+		fmt.Fprintf(b, "{%d}", n.min)
+	default:
+		fmt.Fprintf(b, "{%d,%d}", n.min, n.max)
+	}
+}
+
+// isAtom checks whether node is single char / class, which is repeated without parentheses.
+func isAtom(node regexpNode) bool {
+	switch n := node.(type) {
+	case *regexpChar, *regexpAnyChar, *regexpClass:
+		return true
+	case *regexpConcat:
+		return len(n.items) == 1 && isAtom(n.items[0])
+	default:
+		return false
+	}
+}
+
+func (n *regexpChar) render(b *strings.Builder, flavor regexpFlavor) {
+	metas := `.[]{}()\*+?|^$`
+	if flavor == similarFlavor {
+		metas = `%_[]{}()\*+?|`
+	}
+	if strings.ContainsRune(metas, n.r) {
+		b.WriteRune('\\')
+	}
+	b.WriteRune(n.r)
+}
+
+func (n *regexpAnyChar) render(b *strings.Builder, flavor regexpFlavor) {
+	if flavor == similarFlavor {
+		b.WriteString("_")
+	} else {
+		b.WriteString(".")
+	}
+}
+
+func (n *regexpClass) render(b *strings.Builder, flavor regexpFlavor) {
+	b.WriteString("[")
+	if n.negate {
+		b.WriteString("^")
+	}
+	if flavor == posixFlavor {
+		// backslash isn't escape in posix bracket expression,
+		// so ']' must be the first, '-' must be the last and '^' mustn't be the first.
+		var first, last string
+		var middle []string
+		for _, rg := range n.ranges {
+			switch {
+			case rg[0] == rg[1] && rg[0] == ']':
+				first = "]"
+			case rg[0] == rg[1] && rg[0] == '-':
+				last = "-"
+			case rg[0] == rg[1]:
+				middle = append(middle, string(rg[0]))
+			default:
+				middle = append(middle, string(rg[0])+"-"+string(rg[1]))
+			}
+		}
+		if first == "" && len(middle) != 0 && strings.HasPrefix(middle[0], "^") {
+			middle = append(middle[1:], middle[0])
+		}
+		b.WriteString(first)
+		b.WriteString(strings.Join(middle, ""))
+		if n.alnum {
+			b.WriteString("[:alnum:]")
+		}
+		b.WriteString(last)
+	} else {
+		for _, rg := range n.ranges {
+			writeClassChar(b, rg[0])
+			if rg[0] != rg[1] {
+				b.WriteString("-")
+				writeClassChar(b, rg[1])
+			}
+		}
+		switch {
+		case n.alnum && flavor == re2Flavor:
+			b.WriteString(`\pL\pN`)
+		case n.alnum:
+			b.WriteString("[:alnum:]")
+		}
+	}
+	b.WriteString("]")
+}
+
+func writeClassChar(b *strings.Builder, r rune) {
+	switch r {
+	case ']', '[', '\\', '^', '-':
+		b.WriteRune('\\')
+		b.WriteRune(r)
+	default:
+		b.WriteRune(r)
+	}
+}
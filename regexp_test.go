@@ -0,0 +1,144 @@
+package lucene_to_sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexpMatchToSql(t *testing.T) {
+	type args struct {
+		pattern         string
+		style           SQL_STYLE
+		caseInsensitive bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "test anchored regexp",
+			args: args{pattern: `ab.*`, style: PostgreSQL},
+			want: `field ~ '^ab.*$'`,
+		},
+		{
+			name: "test top level union",
+			args: args{pattern: `ab|cd`, style: PostgreSQL},
+			want: `field ~ '^(ab|cd)$'`,
+		},
+		{
+			name: "test nested union and repeat",
+			args: args{pattern: `a(b|c)+d{2,3}e{2,}`, style: PostgreSQL},
+			want: `field ~ '^a(b|c)+d{2,3}e{2,}$'`,
+		},
+		{
+			name: "test any string",
+			args: args{pattern: `@abc`, style: PostgreSQL},
+			want: `field ~ '^.*abc$'`,
+		},
+		{
+			name: "test quoted string",
+			args: args{pattern: `"a.b"+`, style: PostgreSQL},
+			want: `field ~ '^(a\.b)+$'`,
+		},
+		{
+			name: "test escaped char",
+			args: args{pattern: `a\.b\$`, style: PostgreSQL},
+			want: `field ~ '^a\.b\$$'`,
+		},
+		{
+			name: "test char class",
+			args: args{pattern: `[^a-z\]\-]`, style: PostgreSQL},
+			want: `field ~ '^[^a-z\]\-]$'`,
+		},
+		{
+			name: "test char class of posix",
+			args: args{pattern: `[a\-z\]]`, style: Oracle},
+			want: `regexp_like(field, '^[]az-]$')`,
+		},
+		{
+			name: "test shorthand class",
+			args: args{pattern: `\d+\W`, style: PostgreSQL},
+			want: `field ~ '^[0-9]+[^a-zA-Z0-9_]$'`,
+		},
+		{
+			name: "test fixed width numeric interval",
+			args: args{pattern: `<01-12>`, style: PostgreSQL},
+			want: `field ~ '^(0[1-9]|1[0-2])$'`,
+		},
+		{
+			name: "test numeric interval",
+			args: args{pattern: `foo<1-100>`, style: PostgreSQL},
+			want: `field ~ '^foo0*([1-9]|[1-9][0-9]|100)$'`,
+		},
+		{
+			name: "test numeric interval with partial bounds",
+			args: args{pattern: `<123-456>`, style: PostgreSQL},
+			want: `field ~ '^(12[3-9]|1[3-9][0-9]|[2-3][0-9]{2}|4[0-4][0-9]|45[0-6])$'`,
+		},
+		{
+			name: "test reversed numeric interval",
+			args: args{pattern: `<9-5>`, style: PostgreSQL},
+			want: `field ~ '^[5-9]$'`,
+		},
+		{
+			name: "test similar to",
+			args: args{pattern: `a_b%.c.*`, style: Standard},
+			want: `field SIMILAR TO 'a\_b\%_c%' ESCAPE '\'`,
+		},
+		{
+			name: "test case insensitive by lower",
+			args: args{pattern: `Ab[0-Z]\W|"X.y"`, style: SQLite, caseInsensitive: true},
+			want: `LOWER(field) REGEXP '^(ab[0-Za-z][^a-zA-Z0-9_]|x\.y)$'`,
+		},
+		{
+			name: "test case insensitive similar to",
+			args: args{pattern: `A_B.*`, style: Standard, caseInsensitive: true},
+			want: `LOWER(field) SIMILAR TO 'a\_b%' ESCAPE '\'`,
+		},
+		{
+			name:    "test intersection",
+			args:    args{pattern: `a.*&.*b`, style: PostgreSQL},
+			wantErr: true,
+		},
+		{
+			name:    "test complement",
+			args:    args{pattern: `ab~c`, style: PostgreSQL},
+			wantErr: true,
+		},
+		{
+			name:    "test empty language",
+			args:    args{pattern: `#`, style: PostgreSQL},
+			wantErr: true,
+		},
+		{
+			name:    "test named automaton",
+			args:    args{pattern: `<foo>`, style: PostgreSQL},
+			wantErr: true,
+		},
+		{
+			name:    "test unbalanced parenthesis",
+			args:    args{pattern: `(ab`, style: PostgreSQL},
+			wantErr: true,
+		},
+		{
+			name:    "test nothing to repeat",
+			args:    args{pattern: `*ab`, style: PostgreSQL},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseRegexp(tt.args.pattern)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				cvt := NewSqlConvertor(WithSQLStyle(tt.args.style), WithCaseInsensitiveRegexp(tt.args.caseInsensitive))
+				assert.Equal(t, tt.want, cvt.regexpMatchToSql("field", node))
+			}
+		})
+	}
+}
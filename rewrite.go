@@ -0,0 +1,581 @@
+package lucene_to_sql
+
+import (
+	"strings"
+
+	"github.com/zhuliquan/lucene_parser/token"
+)
+
+// defaultFieldPlaceholder is inserted as the field of term which isn't prefixed with field, e.g. foo is
+// rewritten to \=:foo, because lucene parser requires field of every term. it's replaced by default fields.
+const defaultFieldPlaceholder = `\=`
+
+// queryToken is the token of lucene query and its byte offset in query.
+type queryToken struct {
+	typ    string
+	val    string
+	offset int
+}
+
+// scanQuery splits query into tokens with the lexer of lucene parser.
+func scanQuery(query string) ([]*queryToken, error) {
+	lex, err := token.Lexer.Lex(strings.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	names := map[rune]string{}
+	for name, typ := range token.Lexer.Symbols() {
+		names[typ] = name
+	}
+	var tokens []*queryToken
+	for {
+		tk, err := lex.Next()
+		if err != nil {
+			return nil, err
+		}
+		if tk.EOF() {
+			return tokens, nil
+		}
+		tokens = append(tokens, &queryToken{typ: names[tk.Type], val: tk.Value, offset: tk.Pos.Offset})
+	}
+}
+
+// conjunction of clause, i.e. the operator before clause
+const (
+	conjNone = iota
+	conjAnd
+	conjOr
+)
+
+// modifier of clause
+const (
+	modNone = iota
+	modReq  // +
+	modNot  // - / ! / NOT
+)
+
+// queryLevel is a list of clauses which are joined by operators, e.g. the query in paren.
+type queryLevel struct {
+	clauses []*queryClause
+	// default operator of clauses without explicit operator
+	operator OPERATOR
+}
+
+// queryClause is the clause of lucene query, which is a field term or a sub query surrounded with paren.
+type queryClause struct {
+	field    string // field with colon, it's empty if term doesn't have field
+	term     string // term without sub query
+	open     string // left paren of sub query
+	subQuery *queryLevel
+	close    string // right paren of sub query
+	suffix   string // boost of sub query
+	inGroup  bool   // clause is element of term group, e.g. foo in field:(foo OR bar)
+	offset   int    // byte offset of clause in query
+	end      int    // byte offset after clause in query
+	conj     int
+	mod      int
+}
+
+func (q *queryClause) String() string {
+	var field = q.field
+	if field == "" && !q.inGroup && q.subQuery == nil {
+		field = defaultFieldPlaceholder + ":"
+	}
+	if q.subQuery != nil {
+		return field + q.open + q.subQuery.String() + q.close + q.suffix
+	}
+	return field + q.term
+}
+
+// String joins clauses with explicit operators according to the occurs of clauses in lucene, so that every level
+// (including term group) is matched like lucene whatever operators are used, e.g. a AND b OR c => a AND b.
+// the should clauses are ignored if there are must clauses, because they only affect score in lucene.
+// pure prohibited clauses don't need match all query like lucene, because NOT predicate matches all other rows.
+func (l *queryLevel) String() string {
+	occurs := luceneOccurs(l.clauses, l.operator)
+	var shoulds []string
+	var hasMust bool
+	for i, occur := range occurs {
+		switch occur {
+		case occurMust:
+			hasMust = true
+		case occurShould:
+			shoulds = append(shoulds, l.clauses[i].String())
+		}
+	}
+	// order of clauses is kept, should clauses are put at position of the first one
+	var items []string
+	for i, occur := range occurs {
+		switch {
+		case occur == occurMust:
+			items = append(items, l.clauses[i].String())
+		case occur == occurMustNot:
+			items = append(items, "NOT "+l.clauses[i].String())
+		case hasMust || shoulds == nil:
+		case len(shoulds) > 1 && len(shoulds) != len(occurs):
+			items, shoulds = append(items, "("+strings.Join(shoulds, " OR ")+")"), nil
+		default:
+			items, shoulds = append(items, strings.Join(shoulds, " OR ")), nil
+		}
+	}
+	return strings.Join(items, " AND ")
+}
+
+// droppedClause is the should clause which is dropped by queryLevel.String because of must clauses in the same level,
+// outers are the enclosing sub queries which have field or boost, e.g. b in title:(+a b)^2.
+type droppedClause struct {
+	*queryClause
+	outers []*queryClause
+}
+
+// String returns the clause in syntax of lucene parser with field and boost of enclosing sub queries.
+func (d *droppedClause) String() string {
+	res := d.queryClause.String()
+	for i := len(d.outers) - 1; i >= 0; i-- {
+		res = d.outers[i].field + d.outers[i].open + res + d.outers[i].close + d.outers[i].suffix
+	}
+	return res
+}
+
+// fieldName returns unescaped field of clause or the enclosing term group, e.g. title of b in title:(+a b).
+func (d *droppedClause) fieldName() string {
+	field := d.field
+	for i := len(d.outers) - 1; field == "" && i >= 0; i-- {
+		field = d.outers[i].field
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(field, ":"), "\\", "")
+}
+
+// droppedShoulds returns the should clauses dropped by String in level and its sub queries, clauses in
+// prohibited clauses are skipped, because they don't affect score.
+func (l *queryLevel) droppedShoulds(outers []*queryClause) []*droppedClause {
+	occurs := luceneOccurs(l.clauses, l.operator)
+	var hasMust bool
+	for _, occur := range occurs {
+		hasMust = hasMust || occur == occurMust
+	}
+	var dropped []*droppedClause
+	for i, clause := range l.clauses {
+		if occurs[i] == occurMustNot {
+			continue
+		}
+		if hasMust && occurs[i] == occurShould {
+			dropped = append(dropped, &droppedClause{queryClause: clause, outers: outers})
+		}
+		if clause.subQuery == nil {
+			continue
+		}
+		subOuters := outers
+		if clause.field != "" || clause.suffix != "" {
+			subOuters = append(append([]*queryClause{}, outers...), clause)
+		}
+		dropped = append(dropped, clause.subQuery.droppedShoulds(subOuters)...)
+	}
+	return dropped
+}
+
+// occur of clause in boolean query of lucene
+const (
+	occurShould = iota
+	occurMust
+	occurMustNot
+)
+
+// luceneOccurs returns the occurs of clauses, it's same as QueryParserBase.addClause of lucene.
+func luceneOccurs(clauses []*queryClause, operator OPERATOR) []int {
+	var occurs []int
+	for i, clause := range clauses {
+		// if clause is introduced by AND, make the preceding clause required, unless it's already prohibited
+		if i > 0 && clause.conj == conjAnd && occurs[i-1] != occurMustNot {
+			occurs[i-1] = occurMust
+		}
+		// if clause is introduced by OR, make the preceding clause optional, unless it's already prohibited
+		if i > 0 && operator == AND && clause.conj == conjOr && occurs[i-1] != occurMustNot {
+			occurs[i-1] = occurShould
+		}
+		prohibited := clause.mod == modNot
+		var required bool
+		if operator == OR {
+			required = clause.mod == modReq || (clause.conj == conjAnd && !prohibited)
+		} else {
+			required = !prohibited && clause.conj != conjOr
+		}
+		switch {
+		case prohibited:
+			occurs = append(occurs, occurMustNot)
+		case required:
+			occurs = append(occurs, occurMust)
+		default:
+			occurs = append(occurs, occurShould)
+		}
+	}
+	return occurs
+}
+
+// queryRewriter rewrites lucene syntax which isn't supported by lucene parser into equivalent syntax.
+type queryRewriter struct {
+	tokens   []*queryToken
+	pos      int
+	operator OPERATOR
+}
+
+// parseQueryLevel parses query into clauses, it returns nil if query can't be parsed by rewriter.
+func parseQueryLevel(query string, operator OPERATOR) *queryLevel {
+	tokens, err := scanQuery(query)
+	if err != nil {
+		return nil
+	}
+	r := &queryRewriter{tokens: tokens, operator: operator}
+	level, err := r.parseQuery(false)
+	if err != nil || r.pos != len(r.tokens) {
+		return nil
+	}
+	return level
+}
+
+// clauseSpan is the byte offsets of the beginning and end of clause in query.
+type clauseSpan struct {
+	start, end int
+}
+
+var noSpan = clauseSpan{start: -1, end: -1}
+
+// clauseLocator locates clauses of conversion in original query, it's built by a single walk of parsed query,
+// so that clauses of errors, warnings and explanation are located without parsing query again.
+type clauseLocator struct {
+	// the first clause of field and term
+	clauses map[[2]string]clauseSpan
+	// the first clause of term on default fields, field pattern or _exists_, which matches any field
+	terms map[string]clauseSpan
+	// the first clause of field, term may be normalized by lucene parser, e.g. boost is dropped
+	fields map[string]clauseSpan
+	// sub query which surrounds the clause beginning at offset
+	parents map[int]clauseSpan
+}
+
+func newClauseLocator(level *queryLevel) *clauseLocator {
+	l := &clauseLocator{
+		clauses: map[[2]string]clauseSpan{},
+		terms:   map[string]clauseSpan{},
+		fields:  map[string]clauseSpan{},
+		parents: map[int]clauseSpan{},
+	}
+	if level != nil {
+		l.walk(level, "", noSpan)
+	}
+	return l
+}
+
+func (l *clauseLocator) walk(level *queryLevel, groupField string, parent clauseSpan) {
+	for _, clause := range level.clauses {
+		span := clauseSpan{start: clause.offset, end: clause.end}
+		if parent != noSpan {
+			l.parents[span.start] = parent
+		}
+		name := groupField
+		if clause.field != "" {
+			name = strings.ReplaceAll(strings.TrimSuffix(clause.field, ":"), "\\", "")
+			if _, ok := l.fields[name]; !ok {
+				l.fields[name] = span
+			}
+		}
+		if clause.subQuery != nil {
+			l.walk(clause.subQuery, name, span)
+			continue
+		}
+		value := trimBoost(clause.term)
+		if name == "" || name == existsField || strings.ContainsAny(name, "*?") {
+			if _, ok := l.terms[value]; !ok {
+				l.terms[value] = span
+			}
+		} else if _, ok := l.clauses[[2]string{name, value}]; !ok {
+			l.clauses[[2]string{name, value}] = span
+		}
+	}
+}
+
+// locate returns span of the clause with field and term, the clause on default fields or field pattern is
+// matched with term only. it returns span of the first clause if several clauses are matched.
+func (l *clauseLocator) locate(field, value string) clauseSpan {
+	value = trimBoost(value)
+	span, ok := l.clauses[[2]string{field, value}]
+	if termSpan, termOk := l.terms[value]; termOk && (!ok || termSpan.start < span.start) {
+		span, ok = termSpan, true
+	}
+	if ok {
+		return span
+	}
+	if span, ok = l.fields[field]; ok {
+		return span
+	}
+	return noSpan
+}
+
+// parent returns span of sub query which surrounds the clause beginning at offset.
+func (l *clauseLocator) parent(offset int) (clauseSpan, bool) {
+	span, ok := l.parents[offset]
+	return span, ok
+}
+
+// syntaxError is error of query which is found by rewriter.
+type syntaxError struct {
+	msg    string
+	offset int
+}
+
+func (e *syntaxError) Error() string {
+	return e.msg
+}
+
+// checkQuery returns syntax error of query which is found by scanner and rewriter.
+func checkQuery(query string, operator OPERATOR) error {
+	tokens, err := scanQuery(query)
+	if err != nil {
+		return err
+	}
+	r := &queryRewriter{tokens: tokens, operator: operator}
+	if _, err := r.parseQuery(false); err != nil {
+		return err
+	} else if r.pos != len(r.tokens) {
+		return r.errorf("unexpected right paren")
+	}
+	return nil
+}
+
+// errorf returns syntax error at current position.
+func (r *queryRewriter) errorf(msg string) error {
+	if tk := r.peek(0); tk != nil {
+		return &syntaxError{msg: msg, offset: tk.offset}
+	} else if len(r.tokens) != 0 {
+		last := r.tokens[len(r.tokens)-1]
+		return &syntaxError{msg: msg, offset: last.offset + len(last.val)}
+	}
+	return &syntaxError{msg: msg}
+}
+
+// trimBoost trims boost suffix of term, e.g. foo^2 => foo
+func trimBoost(term string) string {
+	if i := strings.LastIndex(term, "^"); i > 0 && strings.Trim(term[i+1:], "0123456789.") == "" {
+		return term[:i]
+	}
+	return term
+}
+
+func (r *queryRewriter) peek(i int) *queryToken {
+	if r.pos+i < len(r.tokens) {
+		return r.tokens[r.pos+i]
+	}
+	return nil
+}
+
+func (r *queryRewriter) next() *queryToken {
+	tk := r.peek(0)
+	r.pos++
+	return tk
+}
+
+func (r *queryRewriter) is(i int, types ...string) bool {
+	tk := r.peek(i)
+	if tk == nil {
+		return false
+	}
+	for _, typ := range types {
+		if tk.typ == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *queryRewriter) isSpace(i int) bool {
+	return r.is(i, "WHITESPACE", "EOL")
+}
+
+func (r *queryRewriter) isKeyword(i int, keywords ...string) bool {
+	if !r.is(i, "IDENT") {
+		return false
+	}
+	for _, keyword := range keywords {
+		if r.peek(i).val == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOperator returns the operator at current position, includes keyword AND / OR / NOT and symbol && / || / !
+func (r *queryRewriter) parseOperator() (string, int, int) {
+	switch {
+	case r.isKeyword(0, "AND", "and") && r.isSpace(1):
+		return r.next().val, conjAnd, modNone
+	case r.isKeyword(0, "OR", "or") && r.isSpace(1):
+		return r.next().val, conjOr, modNone
+	case r.isKeyword(0, "NOT", "not") && r.isSpace(1):
+		return r.next().val, conjNone, modNot
+	case r.is(0, "AND") && r.is(1, "AND"):
+		return r.next().val + r.next().val, conjAnd, modNone
+	case r.is(0, "SOR") && r.is(1, "SOR"):
+		return r.next().val + r.next().val, conjOr, modNone
+	case r.is(0, "NOT"):
+		return r.next().val, conjNone, modNot
+	default:
+		return "", conjNone, modNone
+	}
+}
+
+// parseQuery parses clauses and operators until right paren or end of query.
+func (r *queryRewriter) parseQuery(inGroup bool) (*queryLevel, error) {
+	level := &queryLevel{operator: r.operator}
+	conj, mod := conjNone, modNone
+	for r.peek(0) != nil && !r.is(0, "RPAREN") {
+		if r.isSpace(0) {
+			r.next()
+		} else if op, opConj, opMod := r.parseOperator(); op != "" {
+			if opConj != conjNone {
+				conj = opConj
+			} else {
+				mod = opMod
+			}
+		} else if r.is(0, "PLUS", "MINUS") && r.peek(1) != nil && !r.isSpace(1) {
+			// required / prohibited modifier, e.g. +foo -bar
+			if r.next().typ == "PLUS" {
+				mod = modReq
+			} else {
+				mod = modNot
+			}
+		} else {
+			clause, err := r.parseClause(inGroup)
+			if err != nil {
+				return nil, err
+			}
+			last := r.tokens[r.pos-1]
+			clause.end = last.offset + len(last.val)
+			clause.conj, clause.mod = conj, mod
+			conj, mod = conjNone, modNone
+			level.clauses = append(level.clauses, clause)
+		}
+	}
+	return level, nil
+}
+
+func (r *queryRewriter) parseClause(inGroup bool) (*queryClause, error) {
+	clause := &queryClause{inGroup: inGroup, offset: r.peek(0).offset}
+	if r.is(0, "LPAREN") {
+		return r.parseSubQuery(clause, inGroup)
+	}
+	if !inGroup {
+		i := 0
+		for r.is(i, "IDENT", "ESCAPE", "MINUS", "NUMBER", "DOT", "WILDCARD") {
+			i++
+		}
+		if i > 0 && r.is(i, "COLON") {
+			for j := 0; j <= i; j++ {
+				if r.is(0, "WILDCARD") {
+					// field pattern, e.g. http.*
+					clause.field += "\\"
+				}
+				clause.field += r.next().val
+			}
+			if r.is(0, "LPAREN") {
+				// term group of field
+				return r.parseSubQuery(clause, true)
+			}
+		}
+	}
+	term, err := r.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	clause.term = term
+	return clause, nil
+}
+
+// parseSubQuery parses query surrounded with paren and the boost behind it.
+func (r *queryRewriter) parseSubQuery(clause *queryClause, inGroup bool) (*queryClause, error) {
+	clause.open = r.next().val
+	subQuery, err := r.parseQuery(inGroup)
+	if err != nil {
+		return nil, err
+	}
+	if !r.is(0, "RPAREN") {
+		return nil, r.errorf("expect right paren")
+	}
+	clause.subQuery = subQuery
+	clause.close = r.next().val
+	clause.suffix = r.parseSuffix()
+	return clause, nil
+}
+
+// parseTerm parses single / phrase / regexp / range term.
+func (r *queryRewriter) parseTerm() (string, error) {
+	var sb strings.Builder
+	switch {
+	case r.is(0, "QUOTE"):
+		sb.WriteString(r.next().val)
+		for !r.is(0, "QUOTE") {
+			if r.peek(0) == nil {
+				return "", r.errorf("expect quote")
+			}
+			if r.is(0, "REVERSE") && r.is(1, "QUOTE") {
+				sb.WriteString(r.next().val)
+			}
+			sb.WriteString(r.next().val)
+		}
+		sb.WriteString(r.next().val)
+	case r.is(0, "SLASH"):
+		sb.WriteString(r.next().val)
+		for !r.is(0, "SLASH") {
+			if r.peek(0) == nil {
+				return "", r.errorf("expect slash")
+			}
+			sb.WriteString(r.next().val)
+		}
+		sb.WriteString(r.next().val)
+	case r.is(0, "COMPARE"):
+		sb.WriteString(r.next().val)
+		value, err := r.parseTerm()
+		if err != nil {
+			return "", err
+		}
+		return sb.String() + value, nil
+	case r.is(0, "LBRACK", "LBRACE"):
+		for !r.is(0, "RBRACK", "RBRACE") {
+			if r.peek(0) == nil {
+				return "", r.errorf("expect right bracket")
+			}
+			if r.is(0, "QUOTE") {
+				phrase, err := r.parseTerm()
+				if err != nil {
+					return "", err
+				}
+				sb.WriteString(phrase)
+				continue
+			}
+			sb.WriteString(r.next().val)
+		}
+		sb.WriteString(r.next().val)
+	default:
+		for r.peek(0) != nil && !r.isSpace(0) && !r.is(0, "LPAREN", "RPAREN") &&
+			!(r.is(0, "AND") && r.is(1, "AND")) && !(r.is(0, "SOR") && r.is(1, "SOR")) {
+			sb.WriteString(r.next().val)
+		}
+		if sb.Len() == 0 {
+			return "", r.errorf("expect term")
+		}
+		return sb.String(), nil
+	}
+	sb.WriteString(r.parseSuffix())
+	return sb.String(), nil
+}
+
+// parseSuffix parses fuzziness / boost of term, e.g. ~2 / ^1.5
+func (r *queryRewriter) parseSuffix() string {
+	var sb strings.Builder
+	if r.is(0, "FUZZY", "BOOST") {
+		sb.WriteString(r.next().val)
+		for r.is(0, "NUMBER", "DOT") {
+			sb.WriteString(r.next().val)
+		}
+	}
+	return sb.String()
+}
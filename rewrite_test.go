@@ -0,0 +1,137 @@
+package lucene_to_sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteQuery(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		query    string
+		operator OPERATOR
+		want     string
+	}{
+		{
+			name:  "test field query",
+			query: `x:1 AND (y:"a b" OR z:[1 TO 2}) AND NOT k:/a b/ || !m:(1 OR 2)^2 && n:>=3`,
+			want:  `x:1 AND (y:"a b" OR z:[1 TO 2}) AND NOT k:/a b/ AND NOT m:(1 OR 2)^2 AND n:>=3`,
+		},
+		{
+			name:  "test term without field",
+			query: `foo AND "foo \"bar" OR (x:1 AND NOT bar~2) AND >5 AND [1 TO 2]`,
+			want:  `\=:foo AND \=:"foo \"bar" AND (x:1 AND NOT \=:bar~2) AND \=:>5 AND \=:[1 TO 2]`,
+		},
+		{
+			name:  "test term group",
+			query: `x:(foo OR (bar AND NOT "baz"))`,
+			want:  `x:(foo OR (bar AND NOT "baz"))`,
+		},
+		{
+			name:  "test wildcard field",
+			query: `http.*:500 OR *.message:"time out" OR x?:(1 OR 2)`,
+			want:  `http.\*:500 OR \*.message:"time out" OR x\?:(1 OR 2)`,
+		},
+		{
+			name:  "test implicit operator",
+			query: `a:1 b:2 NOT c:3`,
+			want:  `(a:1 OR b:2) AND NOT c:3`,
+		},
+		{
+			name:  "test not operator without implicit operator",
+			query: `a:1 NOT c:3`,
+			want:  `a:1 AND NOT c:3`,
+		},
+		{
+			name:  "test required and prohibited modifier",
+			query: `+a:1 -b:2 c:3 +(d:4 e:5)^2`,
+			want:  `a:1 AND NOT b:2 AND (d:4 OR e:5)^2`,
+		},
+		{
+			name:  "test pure prohibited modifier",
+			query: `-a:1 !b:2`,
+			want:  `NOT a:1 AND NOT b:2`,
+		},
+		{
+			name:  "test explicit and operator makes clauses required",
+			query: `a:1 AND b:2 c:3 || d:4`,
+			want:  `a:1 AND b:2`,
+		},
+		{
+			name:  "test modifier in term group",
+			query: `x:(+a -"b c" d) AND y:1`,
+			want:  `x:(a AND NOT "b c") AND y:1`,
+		},
+		{
+			name:  "test modifier of term without field",
+			query: `foo +bar -baz`,
+			want:  `\=:bar AND NOT \=:baz`,
+		},
+		{
+			name:     "test implicit operator with and default operator",
+			query:    `a:1 b:2 NOT c:3`,
+			operator: AND,
+			want:     `a:1 AND b:2 AND NOT c:3`,
+		},
+		{
+			name:     "test or operator with and default operator",
+			query:    `a:1 OR b:2 -d:4`,
+			operator: AND,
+			want:     `(a:1 OR b:2) AND NOT d:4`,
+		},
+		{
+			name:  "test explicit operators",
+			query: `a:1 AND b:2 OR c:3`,
+			want:  `a:1 AND b:2`,
+		},
+		{
+			name:  "test explicit and implicit operators",
+			query: `a:1 AND b:2 OR c:3 d:4`,
+			want:  `a:1 AND b:2`,
+		},
+		{
+			name:  "test or operators",
+			query: `a:1 OR b:2 OR NOT c:3`,
+			want:  `(a:1 OR b:2) AND NOT c:3`,
+		},
+		{
+			name:  "test query can't be rewritten",
+			query: `x:(foo OR bar`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			level := parseQueryLevel(tt.query, tt.operator)
+			if tt.want == "" {
+				assert.Nil(t, level)
+				return
+			}
+			assert.Equal(t, tt.want, level.String())
+		})
+	}
+}
+
+func TestClauseLocator(t *testing.T) {
+	query := `a:1^2 AND (foo OR b:(x OR "y z")) AND _exists_:c AND http.\*:500 AND b:foo`
+	locator := newClauseLocator(parseQueryLevel(query, OR))
+	for _, tt := range []struct {
+		name  string
+		field string
+		term  string
+		want  clauseSpan
+	}{
+		{name: "test boosted term", field: "a", term: "1", want: clauseSpan{start: 0, end: 5}},
+		{name: "test term on default field", field: "title", term: "foo", want: clauseSpan{start: 11, end: 14}},
+		{name: "test term in group", field: "b", term: `"y z"`, want: clauseSpan{start: 26, end: 31}},
+		{name: "test normalized term group", field: "b", term: `( x OR "y z" )`, want: clauseSpan{start: 18, end: 32}},
+		{name: "test exists", field: "c", term: "c", want: clauseSpan{start: 38, end: 48}},
+		{name: "test field pattern", field: "http.*", term: "500", want: clauseSpan{start: 53, end: 64}},
+		{name: "test first matched clause", field: "b", term: "foo", want: clauseSpan{start: 11, end: 14}},
+		{name: "test unknown clause", field: "d", term: "1", want: noSpan},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, locator.locate(tt.field, tt.term))
+		})
+	}
+	assert.Equal(t, noSpan, newClauseLocator(parseQueryLevel("a:(1", OR)).locate("a", "1"))
+}
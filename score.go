@@ -0,0 +1,90 @@
+package lucene_to_sql
+
+import (
+	"fmt"
+	"strings"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+	"github.com/zhuliquan/lucene_parser/term"
+)
+
+// multiplyBoost multiplies boost of clauses in term by b, and returns function which restores boost.
+func (c *SqlConvertor) multiplyBoost(b float64) func() {
+	boost := c.boost
+	c.boost *= b
+	return func() {
+		c.boost = boost
+	}
+}
+
+// addScore adds score of clause, which is rank of full text search if rank isn't empty,
+// otherwise it's boost of clause if clause is matched.
+func (c *SqlConvertor) addScore(sql, rank string) {
+	if !c.scoring || sql == falsePredicate {
+		return
+	}
+	switch {
+	case rank == "":
+		c.scores = append(c.scores, fmt.Sprintf("CASE WHEN %s THEN %g ELSE 0 END", sql, c.boost))
+	case c.boost == 1:
+		c.scores = append(c.scores, rank)
+	default:
+		c.scores = append(c.scores, fmt.Sprintf("%s * %g", rank, c.boost))
+	}
+}
+
+// scoreToSql returns sum of scores of clauses, which can be used as ORDER BY score DESC.
+func (c *SqlConvertor) scoreToSql() string {
+	if !c.scoring {
+		return ""
+	} else if len(c.scores) == 0 {
+		return "0"
+	}
+	return strings.Join(c.scores, " + ")
+}
+
+// fullTextRank returns rank of term on text field by full text search, it's empty if sql style
+// can't rank the term, e.g. SQLite whose bm25 requires table.
+func (c *SqlConvertor) fullTextRank(field string, tType *esMapping.Property, value *term.Term) string {
+	if !c.scoring || !c.rankFullText || !esMapping.CheckTextType(tType.Type) {
+		return ""
+	}
+	var val string
+	var phrase bool
+	switch termType := getTermType(value); {
+	case termType&term.REGEXP_TERM_TYPE == term.REGEXP_TERM_TYPE,
+		termType&term.RANGE_TERM_TYPE == term.RANGE_TERM_TYPE,
+		termType&term.WILDCARD_TERM_TYPE == term.WILDCARD_TERM_TYPE,
+		termType&term.FUZZY_TERM_TYPE == term.FUZZY_TERM_TYPE:
+		return ""
+	case termType&term.SINGLE_TERM_TYPE == term.SINGLE_TERM_TYPE:
+		val = value.String()
+	case termType&term.PHRASE_TERM_TYPE == term.PHRASE_TERM_TYPE:
+		val, phrase = strings.Trim(value.String(), "\""), true
+	default:
+		return ""
+	}
+	switch c.sqlStyle {
+	case PostgreSQL:
+		return fmt.Sprintf("ts_rank(to_tsvector(%s), %s)", field, tsquery(val, phrase))
+	case MySQL:
+		// relevance of natural language / boolean mode
+		return fmt.Sprintf("MATCH (%s) AGAINST (%s)", field, mysqlAgainst(val, phrase))
+	default:
+		return ""
+	}
+}
+
+func tsquery(val string, phrase bool) string {
+	if phrase {
+		return fmt.Sprintf("phraseto_tsquery(%s)", quoteString(val))
+	}
+	return fmt.Sprintf("plainto_tsquery(%s)", quoteString(val))
+}
+
+func mysqlAgainst(val string, phrase bool) string {
+	if phrase {
+		return quoteString("\""+strings.ReplaceAll(val, "\"", "")+"\"") + " IN BOOLEAN MODE"
+	}
+	return quoteString(val) + " IN NATURAL LANGUAGE MODE"
+}
@@ -0,0 +1,130 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestScore(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"title": {
+				Type: esMapping.TEXT_FIELD_TYPE,
+			},
+			"body": {
+				Type: esMapping.TEXT_FIELD_TYPE,
+			},
+			"status": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+		},
+	}))
+	tests := []struct {
+		name      string
+		opts      []func(*SqlConvertor)
+		query     string
+		wantSQL   string
+		wantScore string
+	}{
+		{
+			name:      "test boost",
+			opts:      []func(*SqlConvertor){schema, WithScoring(true)},
+			query:     "title:foo^3 OR body:foo",
+			wantSQL:   "title like '%foo%' OR body like '%foo%'",
+			wantScore: "CASE WHEN title like '%foo%' THEN 3 ELSE 0 END + CASE WHEN body like '%foo%' THEN 1 ELSE 0 END",
+		},
+		{
+			name:      "test not clause isn't scored",
+			opts:      []func(*SqlConvertor){schema, WithScoring(true)},
+			query:     "status:a^2 AND NOT status:b AND _exists_:title",
+			wantSQL:   "status = 'a' AND NOT ( status = 'b' ) AND title IS NOT NULL",
+			wantScore: "CASE WHEN status = 'a' THEN 2 ELSE 0 END + CASE WHEN title IS NOT NULL THEN 1 ELSE 0 END",
+		},
+		{
+			name:      "test boost of term group",
+			opts:      []func(*SqlConvertor){schema, WithScoring(true)},
+			query:     "status:(a OR b)^1.5 OR title:(x AND y)^2",
+			wantSQL:   "status IN ('a', 'b') OR ( title like '%x%' AND title like '%y%' )",
+			wantScore: "CASE WHEN status IN ('a', 'b') THEN 1.5 ELSE 0 END + CASE WHEN title like '%x%' THEN 2 ELSE 0 END + CASE WHEN title like '%y%' THEN 2 ELSE 0 END",
+		},
+		{
+			name:      "test default fields",
+			opts:      []func(*SqlConvertor){schema, WithScoring(true), WithDefaultFields("title", "body")},
+			query:     "foo^2",
+			wantSQL:   "( body like '%foo%' OR title like '%foo%' )",
+			wantScore: "CASE WHEN body like '%foo%' THEN 2 ELSE 0 END + CASE WHEN title like '%foo%' THEN 2 ELSE 0 END",
+		},
+		{
+			name:      "test should clause dropped by must clause is scored",
+			opts:      []func(*SqlConvertor){schema, WithScoring(true)},
+			query:     "+status:a title:b^2",
+			wantSQL:   "status = 'a'",
+			wantScore: "CASE WHEN status = 'a' THEN 1 ELSE 0 END + CASE WHEN title like '%b%' THEN 2 ELSE 0 END",
+		},
+		{
+			name:      "test should clause dropped in sub query is scored",
+			opts:      []func(*SqlConvertor){schema, WithScoring(true)},
+			query:     "status:a AND title:(+x y)^2 AND NOT (+body:c body:d) AND NOT title:(z OR w)",
+			wantSQL:   "status = 'a' AND title like '%x%' AND  NOT ( body like '%c%' ) AND NOT ( ( title like '%z%' OR title like '%w%' ) )",
+			wantScore: "CASE WHEN status = 'a' THEN 1 ELSE 0 END + CASE WHEN title like '%x%' THEN 2 ELSE 0 END + CASE WHEN title like '%y%' THEN 2 ELSE 0 END",
+		},
+		{
+			name:      "test prohibited term on default fields isn't scored",
+			opts:      []func(*SqlConvertor){schema, WithScoring(true), WithDefaultFields("title")},
+			query:     "status:a AND NOT foo",
+			wantSQL:   "status = 'a' AND NOT ( title like '%foo%' )",
+			wantScore: "CASE WHEN status = 'a' THEN 1 ELSE 0 END",
+		},
+		{
+			name:      "test no clause",
+			opts:      []func(*SqlConvertor){schema, WithScoring(true)},
+			query:     "NOT status:a",
+			wantSQL:   "NOT ( status = 'a' )",
+			wantScore: "0",
+		},
+		{
+			name:    "test scoring off",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "status:a^2",
+			wantSQL: "status = 'a'",
+		},
+		{
+			name:      "test postgresql full text rank",
+			opts:      []func(*SqlConvertor){schema, WithSQLStyle(PostgreSQL), WithFullTextRank(true), WithScoring(true)},
+			query:     "title:foo^3 OR body:\"foo bar\" OR status:a",
+			wantSQL:   "title like '%foo%' OR body like '%foo bar%' OR status = 'a'",
+			wantScore: "ts_rank(to_tsvector(title), plainto_tsquery('foo')) * 3 + ts_rank(to_tsvector(body), phraseto_tsquery('foo bar')) + CASE WHEN status = 'a' THEN 1 ELSE 0 END",
+		},
+		{
+			name:      "test mysql full text rank",
+			opts:      []func(*SqlConvertor){schema, WithSQLStyle(MySQL), WithFullTextRank(true), WithScoring(true)},
+			query:     "title:foo OR body:\"foo bar\"^2",
+			wantSQL:   "title like '%foo%' OR body like '%foo bar%'",
+			wantScore: "MATCH (title) AGAINST ('foo' IN NATURAL LANGUAGE MODE) + MATCH (body) AGAINST ('\"foo bar\"' IN BOOLEAN MODE) * 2",
+		},
+		{
+			name:      "test sqlite full text rank falls back to boost",
+			opts:      []func(*SqlConvertor){schema, WithSQLStyle(SQLite), WithFullTextRank(true), WithScoring(true)},
+			query:     "title:foo AND body:\"foo bar\"",
+			wantSQL:   "title like '%foo%' AND body like '%foo bar%'",
+			wantScore: "CASE WHEN title like '%foo%' THEN 1 ELSE 0 END + CASE WHEN body like '%foo bar%' THEN 1 ELSE 0 END",
+		},
+		{
+			name:    "test full text rank without scoring",
+			opts:    []func(*SqlConvertor){schema, WithSQLStyle(PostgreSQL), WithFullTextRank(true)},
+			query:   "title:foo",
+			wantSQL: "title like '%foo%'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewSqlConvertor(tt.opts...).Convert(context.Background(), tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSQL, res.SQL)
+			assert.Equal(t, tt.wantScore, res.Score)
+		})
+	}
+}
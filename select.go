@@ -0,0 +1,201 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ScoreField is the sort field which sorts rows by Result.Score, scoring is enabled by it.
+const ScoreField = "_score"
+
+// SortField is the field of ORDER BY, e.g. {Field: "age", Desc: true} => age DESC.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// LimitBy limits rows of every group of columns in ClickHouse, i.e. LIMIT n BY columns.
+type LimitBy struct {
+	Limit   int
+	Columns []string
+}
+
+// Select is SELECT statement whose WHERE clause is converted from lucene query.
+type Select struct {
+	Table string
+	// Columns are projections of statement, empty means *.
+	Columns []string
+	Sort    []*SortField
+	// Limit is max count of rows, zero means no limit.
+	Limit  int
+	Offset int
+	// LimitBy is only supported by ClickHouse.
+	LimitBy *LimitBy
+}
+
+// SelectToSql converts lucene query to WHERE clause of SELECT statement, and renders statement in sql style.
+// Result.SQL is the whole statement, args of filters are returned as parameters like Convert.
+func (c *SqlConvertor) SelectToSql(
+	ctx context.Context, stmt *Select, query string, options ...func(s *SqlConvertor),
+) (*Result, error) {
+	if stmt.Table == "" {
+		return nil, fmt.Errorf("table of select is empty")
+	} else if stmt.Limit < 0 || stmt.Offset < 0 {
+		return nil, fmt.Errorf("limit: %d and offset: %d of select must not be negative", stmt.Limit, stmt.Offset)
+	}
+	cvt := c.clone()
+	for _, opt := range options {
+		opt(cvt)
+	}
+	for _, sort := range stmt.Sort {
+		if sort.Field == ScoreField {
+			cvt.scoring = true
+		}
+	}
+	res, err := cvt.convert(ctx, query, false)
+	if err != nil {
+		return nil, err
+	}
+	if res.SQL, err = cvt.selectToSql(stmt, res.SQL, res.Score); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *SqlConvertor) selectToSql(stmt *Select, where, score string) (string, error) {
+	if stmt.LimitBy != nil && c.sqlStyle != ClickHouse {
+		return "", &UnsupportedDialectFeatureError{Clause: Clause{Offset: -1}, Dialect: c.sqlStyle, Feature: "LIMIT BY"}
+	}
+	if !isIdentifier(stmt.Table) {
+		return "", fmt.Errorf("table: %s of select isn't identifier", stmt.Table)
+	}
+	columns, err := c.selectColumns(stmt.Columns)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	// TOP can't be used with OFFSET in SQL Server
+	if c.sqlStyle == SQLServer && stmt.Limit > 0 && stmt.Offset == 0 {
+		fmt.Fprintf(&b, "TOP (%d) ", stmt.Limit)
+	}
+	if len(columns) == 0 {
+		b.WriteString("*")
+	} else {
+		b.WriteString(strings.Join(columns, ", "))
+	}
+	clauses := []string{b.String(), "FROM " + stmt.Table, "WHERE " + where}
+	if c.format != nil && c.format.MultiLine {
+		// predicate is in its own lines
+		clauses[2] = "WHERE\n" + c.format.indent() + strings.ReplaceAll(where, "\n", "\n"+c.format.indent())
+	}
+
+	var orders []string
+	for _, sort := range stmt.Sort {
+		var order string
+		if sort.Field == ScoreField {
+			if score == "0" {
+				// every row has same score, and ORDER BY 0 means position of column
+				continue
+			}
+			order = score
+		} else if order, err = c.selectColumn(sort.Field); err != nil {
+			return "", err
+		}
+		if sort.Desc {
+			order += " DESC"
+		}
+		orders = append(orders, order)
+	}
+	if len(orders) == 0 && c.sqlStyle == SQLServer && stmt.Offset > 0 {
+		// OFFSET requires ORDER BY in SQL Server
+		orders = append(orders, "(SELECT NULL)")
+	}
+	if len(orders) != 0 {
+		clauses = append(clauses, "ORDER BY "+strings.Join(orders, ", "))
+	}
+	if stmt.LimitBy != nil {
+		columns, err := c.selectColumns(stmt.LimitBy.Columns)
+		if err != nil {
+			return "", err
+		}
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d BY %s", stmt.LimitBy.Limit, strings.Join(columns, ", ")))
+	}
+	if paging := c.pagingToSql(stmt.Limit, stmt.Offset); paging != "" {
+		clauses = append(clauses, paging)
+	}
+	if c.format != nil && c.format.MultiLine {
+		return strings.Join(clauses, "\n"), nil
+	}
+	return strings.Join(clauses, " "), nil
+}
+
+// selectColumn checks field of statement against field policy and returns its column, e.g. value of map column.
+// field must be identifier, because it's put into sql as it is.
+func (c *SqlConvertor) selectColumn(field string) (string, error) {
+	if !isIdentifier(field) {
+		return "", fmt.Errorf("field: %s of select isn't identifier", field)
+	} else if !c.fieldPolicy.Allowed(field) {
+		// denied field can't be replaced by predicate in statement
+		return "", &FieldDeniedError{Clause: Clause{Field: field, Offset: -1}}
+	}
+	if prefix, key, ok := c.splitMapField(field); ok {
+		mapField := c.mapFields[prefix]
+		return c.mapValueToSql(prefix, key, mapField, c.mapKeyProperty(field, mapField)), nil
+	}
+	return field, nil
+}
+
+func (c *SqlConvertor) selectColumns(fields []string) ([]string, error) {
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, err := c.selectColumn(field)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// pagingToSql returns LIMIT / OFFSET of statement in sql style.
+func (c *SqlConvertor) pagingToSql(limit, offset int) string {
+	switch c.sqlStyle {
+	case SQLServer:
+		if offset == 0 {
+			// limit is TOP
+			return ""
+		} else if limit == 0 {
+			return fmt.Sprintf("OFFSET %d ROWS", offset)
+		}
+		return fmt.Sprintf("OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit)
+	case Standard, Oracle:
+		switch {
+		case limit > 0 && offset > 0:
+			return fmt.Sprintf("OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit)
+		case limit > 0:
+			return fmt.Sprintf("FETCH FIRST %d ROWS ONLY", limit)
+		case offset > 0:
+			return fmt.Sprintf("OFFSET %d ROWS", offset)
+		default:
+			return ""
+		}
+	default:
+		switch {
+		case limit > 0 && offset > 0:
+			return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+		case limit > 0:
+			return fmt.Sprintf("LIMIT %d", limit)
+		case offset > 0 && c.sqlStyle == MySQL:
+			// max of unsigned bigint, which is suggested by manual of mysql
+			return fmt.Sprintf("LIMIT 18446744073709551615 OFFSET %d", offset)
+		case offset > 0 && c.sqlStyle == SQLite:
+			return fmt.Sprintf("LIMIT -1 OFFSET %d", offset)
+		case offset > 0:
+			return fmt.Sprintf("OFFSET %d", offset)
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestSelectToSql(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"status": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+			"title": {
+				Type: esMapping.TEXT_FIELD_TYPE,
+			},
+		},
+	}))
+	page := &Select{
+		Table:   "logs",
+		Columns: []string{"id", "status"},
+		Sort:    []*SortField{{Field: "ts", Desc: true}, {Field: "id"}},
+		Limit:   10,
+		Offset:  20,
+	}
+	top := &Select{Table: "logs", Limit: 10}
+	skip := &Select{Table: "logs", Offset: 20}
+	tests := []struct {
+		name    string
+		style   SQL_STYLE
+		stmt    *Select
+		query   string
+		wantSQL string
+		wantErr bool
+	}{
+		{
+			name:    "test mysql page",
+			style:   MySQL,
+			stmt:    page,
+			query:   "status:200",
+			wantSQL: "SELECT id, status FROM logs WHERE status = '200' ORDER BY ts DESC, id LIMIT 10 OFFSET 20",
+		},
+		{
+			name:    "test mysql offset",
+			style:   MySQL,
+			stmt:    skip,
+			query:   "status:200",
+			wantSQL: "SELECT * FROM logs WHERE status = '200' LIMIT 18446744073709551615 OFFSET 20",
+		},
+		{
+			name:    "test sqlite offset",
+			style:   SQLite,
+			stmt:    skip,
+			query:   "status:200",
+			wantSQL: "SELECT * FROM logs WHERE status = '200' LIMIT -1 OFFSET 20",
+		},
+		{
+			name:    "test postgresql offset",
+			style:   PostgreSQL,
+			stmt:    skip,
+			query:   "status:200",
+			wantSQL: "SELECT * FROM logs WHERE status = '200' OFFSET 20",
+		},
+		{
+			name:    "test oracle page",
+			style:   Oracle,
+			stmt:    page,
+			query:   "status:200",
+			wantSQL: "SELECT id, status FROM logs WHERE status = '200' ORDER BY ts DESC, id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:    "test standard limit",
+			style:   Standard,
+			stmt:    top,
+			query:   "status:200",
+			wantSQL: "SELECT * FROM logs WHERE status = '200' FETCH FIRST 10 ROWS ONLY",
+		},
+		{
+			name:    "test sql server top",
+			style:   SQLServer,
+			stmt:    top,
+			query:   "status:200",
+			wantSQL: "SELECT TOP (10) * FROM logs WHERE status = '200'",
+		},
+		{
+			name:    "test sql server page",
+			style:   SQLServer,
+			stmt:    page,
+			query:   "status:200",
+			wantSQL: "SELECT id, status FROM logs WHERE status = '200' ORDER BY ts DESC, id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:    "test sql server offset without order",
+			style:   SQLServer,
+			stmt:    skip,
+			query:   "status:200",
+			wantSQL: "SELECT * FROM logs WHERE status = '200' ORDER BY (SELECT NULL) OFFSET 20 ROWS",
+		},
+		{
+			name:  "test clickhouse limit by",
+			style: ClickHouse,
+			stmt: &Select{
+				Table:   "logs",
+				Sort:    []*SortField{{Field: "ts", Desc: true}},
+				LimitBy: &LimitBy{Limit: 2, Columns: []string{"host", "status"}},
+				Limit:   100,
+			},
+			query:   "status:200",
+			wantSQL: "SELECT * FROM logs WHERE status = '200' ORDER BY ts DESC LIMIT 2 BY host, status LIMIT 100",
+		},
+		{
+			name:    "test limit by of other style",
+			style:   MySQL,
+			stmt:    &Select{Table: "logs", LimitBy: &LimitBy{Limit: 2, Columns: []string{"host"}}},
+			query:   "status:200",
+			wantErr: true,
+		},
+		{
+			name:  "test sort by score",
+			style: PostgreSQL,
+			stmt: &Select{
+				Table: "docs",
+				Sort:  []*SortField{{Field: ScoreField, Desc: true}, {Field: "id"}},
+				Limit: 5,
+			},
+			query:   "title:foo^2 OR status:new",
+			wantSQL: "SELECT * FROM docs WHERE title like '%foo%' OR status = 'new' ORDER BY CASE WHEN title like '%foo%' THEN 2 ELSE 0 END + CASE WHEN status = 'new' THEN 1 ELSE 0 END DESC, id LIMIT 5",
+		},
+		{
+			name:  "test sort by score without scored clause",
+			style: PostgreSQL,
+			stmt: &Select{
+				Table: "docs",
+				Sort:  []*SortField{{Field: ScoreField, Desc: true}},
+			},
+			query:   "NOT status:new",
+			wantSQL: "SELECT * FROM docs WHERE NOT ( status = 'new' )",
+		},
+		{
+			name:    "test empty table",
+			style:   MySQL,
+			stmt:    &Select{},
+			query:   "status:200",
+			wantErr: true,
+		},
+		{
+			name:    "test table isn't identifier",
+			style:   MySQL,
+			stmt:    &Select{Table: "logs; DROP TABLE logs"},
+			query:   "status:200",
+			wantErr: true,
+		},
+		{
+			name:    "test column isn't identifier",
+			style:   MySQL,
+			stmt:    &Select{Table: "logs", Columns: []string{"id", "(SELECT password FROM users)"}},
+			query:   "status:200",
+			wantErr: true,
+		},
+		{
+			name:    "test sort field isn't identifier",
+			style:   MySQL,
+			stmt:    &Select{Table: "logs", Sort: []*SortField{{Field: "id; DROP TABLE logs"}}},
+			query:   "status:200",
+			wantErr: true,
+		},
+		{
+			name:    "test limit by column isn't identifier",
+			style:   ClickHouse,
+			stmt:    &Select{Table: "logs", LimitBy: &LimitBy{Limit: 1, Columns: []string{"host)"}}},
+			query:   "status:200",
+			wantErr: true,
+		},
+		{
+			name:    "test negative limit",
+			style:   MySQL,
+			stmt:    &Select{Table: "logs", Limit: -1},
+			query:   "status:200",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewSqlConvertor(schema, WithSQLStyle(tt.style)).SelectToSql(context.Background(), tt.stmt, tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantSQL, res.SQL)
+			}
+		})
+	}
+}
+
+func TestSelectToSqlWithFilter(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSQLStyle(SQLServer),
+		WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldDefaultType}),
+		WithFilter("tenant = ?", 7),
+	)
+	res, err := cvt.SelectToSql(context.Background(), &Select{Table: "logs", Limit: 1}, "status:200")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT TOP (1) * FROM logs WHERE ( status = '200' ) AND ( tenant = @p1 )", res.SQL)
+	assert.Equal(t, []interface{}{7}, res.Args)
+}
+
+func TestSelectToSqlColumns(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSQLStyle(ClickHouse),
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"status": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+			},
+		})),
+		WithMapField("labels", &MapField{}),
+		WithFieldPolicy(&FieldPolicy{Deny: []string{"ssn"}, Mode: DenyFalse}),
+	)
+	res, err := cvt.SelectToSql(context.Background(), &Select{
+		Table:   "db.logs",
+		Columns: []string{"id", "labels.env"},
+		Sort:    []*SortField{{Field: "labels.env", Desc: true}},
+	}, "status:200")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id, labels['env'] FROM db.logs WHERE status = '200' ORDER BY labels['env'] DESC", res.SQL)
+
+	for _, stmt := range []*Select{
+		{Table: "logs", Columns: []string{"SSN"}},
+		{Table: "logs", Sort: []*SortField{{Field: "ssn"}}},
+		{Table: "logs", LimitBy: &LimitBy{Limit: 1, Columns: []string{"ssn"}}},
+	} {
+		_, err = cvt.SelectToSql(context.Background(), stmt, "status:200")
+		var deniedErr *FieldDeniedError
+		assert.ErrorAs(t, err, &deniedErr)
+	}
+
+	_, err = NewSqlConvertor(WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"status": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+		},
+	})), WithSQLStyle(MySQL)).SelectToSql(context.Background(), &Select{
+		Table: "logs", LimitBy: &LimitBy{Limit: 1, Columns: []string{"host"}},
+	}, "status:200")
+	assert.EqualError(t, err, "MySQL doesn't support LIMIT BY")
+}
@@ -4,6 +4,8 @@ import (
 	"strings"
 )
 
+// SQL builds predicates, empty clause (e.g. dropped clause) is skipped
+// and symbol isn't written before the first clause.
 type SQL struct {
 	buff *strings.Builder
 }
@@ -15,14 +17,20 @@ func NewSQL() *SQL {
 }
 
 func (s *SQL) AddORClause(clause string, orSymbol bool) {
-	if orSymbol {
+	if clause == "" {
+		return
+	}
+	if orSymbol && s.buff.Len() != 0 {
 		_, _ = s.buff.WriteString(" OR ")
 	}
 	_, _ = s.buff.WriteString(clause)
 }
 
 func (s *SQL) AddAndClause(clause string, andSymbol, notSymbol bool) {
-	if andSymbol {
+	if clause == "" {
+		return
+	}
+	if andSymbol && s.buff.Len() != 0 {
 		_, _ = s.buff.WriteString(" AND ")
 	}
 	if notSymbol {
@@ -32,6 +40,9 @@ func (s *SQL) AddAndClause(clause string, andSymbol, notSymbol bool) {
 }
 
 func (s *SQL) AddSubClause(clause string, notSymbol bool) {
+	if clause == "" {
+		return
+	}
 	if notSymbol {
 		_, _ = s.buff.WriteString(" NOT ")
 	}
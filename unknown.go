@@ -0,0 +1,88 @@
+package lucene_to_sql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+// UNKNOWN_FIELD_MODE is what to do with the field which isn't in schema.
+type UNKNOWN_FIELD_MODE int32
+
+const (
+	UnknownFieldStrict      UNKNOWN_FIELD_MODE = iota // fail conversion
+	UnknownFieldIgnore                                // clause on unknown field matches nothing with a warning, like unmapped field of ES
+	UnknownFieldDefaultType                           // treat unknown field whose name is identifier as a column of default type
+	UnknownFieldDynamic                               // route unknown field to the key of dynamic json column
+)
+
+// UnknownFieldPolicy decides how to query field which isn't in schema, like dynamic / unmapped field of ES.
+type UnknownFieldPolicy struct {
+	Mode UNKNOWN_FIELD_MODE
+	// DefaultType is type of unknown field in UnknownFieldDefaultType / UnknownFieldDynamic mode, default is keyword.
+	DefaultType esMapping.FieldType
+	// Column is json column which stores unknown fields in UnknownFieldDynamic mode.
+	Column string
+}
+
+// errFieldIgnored means the clause on unknown field matches nothing.
+var errFieldIgnored = errors.New("unknown field is ignored")
+
+// unknownFieldProperty returns column expression and property of unknown field according to policy.
+func (c *SqlConvertor) unknownFieldProperty(field string, err error) (string, *esMapping.Property, error) {
+	policy := c.unknownFieldPolicy
+	if policy == nil || policy.Mode == UnknownFieldStrict {
+		var unknown *UnknownFieldError
+		if errors.As(err, &unknown) {
+			return "", nil, err
+		}
+		return "", nil, fmt.Errorf("failed to get field: %s property, err: %w", field, err)
+	}
+	tType := &esMapping.Property{Type: policy.DefaultType}
+	if tType.Type == esMapping.UNKNOWN_FIELD_TYPE {
+		tType.Type = esMapping.KEYWORD_FIELD_TYPE
+	}
+	switch policy.Mode {
+	case UnknownFieldIgnore:
+		c.warn(UnknownFieldDropped, "clause on unknown field: %s matches nothing", field)
+		return "", nil, errFieldIgnored
+	case UnknownFieldDynamic:
+		mapField := &MapField{DefaultType: tType.Type}
+		return c.mapValueToSql(policy.Column, field, mapField, tType), tType, nil
+	default:
+		if !isIdentifier(field) {
+			// field is used as column, e.g. a\(b can't be column
+			return "", nil, &UnknownFieldError{Clause: Clause{Field: field}}
+		}
+		return field, tType, nil
+	}
+}
+
+// isIdentifier checks whether field is unquoted identifier of column, which may be qualified, e.g. user.name
+func isIdentifier(field string) bool {
+	for _, part := range strings.Split(field, ".") {
+		if part == "" {
+			return false
+		}
+		for i, r := range part {
+			if !(r == '_' || unicode.IsLetter(r) || i != 0 && unicode.IsDigit(r)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// unknownExistsToSql converts exists query of unknown field according to policy.
+func (c *SqlConvertor) unknownExistsToSql(field string, err error) (string, error) {
+	if _, _, err = c.unknownFieldProperty(field, err); err != nil {
+		return "", err
+	}
+	if c.unknownFieldPolicy.Mode == UnknownFieldDynamic {
+		return c.mapContainsToSql(c.unknownFieldPolicy.Column, field, &MapField{}), nil
+	}
+	return fmt.Sprintf("%s IS NOT NULL", field), nil
+}
@@ -0,0 +1,130 @@
+package lucene_to_sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestUnknownFieldPolicy(t *testing.T) {
+	schema := WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"a": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+		},
+	}))
+	tests := []struct {
+		name    string
+		opts    []func(*SqlConvertor)
+		query   string
+		wantSQL string
+		wantErr bool
+	}{
+		{
+			name:    "test strict",
+			opts:    []func(*SqlConvertor){schema},
+			query:   "a:1 AND b:2",
+			wantErr: true,
+		},
+		{
+			name:    "test ignore",
+			opts:    []func(*SqlConvertor){schema, WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldIgnore})},
+			query:   "b:2 OR a:1 AND NOT b:3",
+			wantSQL: "a = '1' AND NOT ( 1 = 0 )",
+		},
+		{
+			name:    "test ignore sub query",
+			opts:    []func(*SqlConvertor){schema, WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldIgnore})},
+			query:   "a:1 AND NOT (b:2 OR b:(3 OR 4) OR _exists_:c)",
+			wantSQL: "a = '1' AND  NOT ( 1 = 0 OR 1 = 0 OR 1 = 0 )",
+		},
+		{
+			name:    "test ignore matches nothing",
+			opts:    []func(*SqlConvertor){schema, WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldIgnore})},
+			query:   "b:2 OR _exists_:c",
+			wantSQL: "1 = 0 OR 1 = 0",
+		},
+		{
+			name: "test default type",
+			opts: []func(*SqlConvertor){schema, WithUnknownFieldPolicy(&UnknownFieldPolicy{
+				Mode:        UnknownFieldDefaultType,
+				DefaultType: esMapping.LONG_FIELD_TYPE,
+			})},
+			query:   "a:1 AND b:[1 TO 2] AND _exists_:c",
+			wantSQL: "a = '1' AND b >= 1 AND b <= 2 AND c IS NOT NULL",
+		},
+		{
+			name:    "test default keyword type",
+			opts:    []func(*SqlConvertor){schema, WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldDefaultType})},
+			query:   "b:(x OR y)",
+			wantSQL: "b IN ('x', 'y')",
+		},
+		{
+			name:    "test default type of field isn't identifier",
+			opts:    []func(*SqlConvertor){schema, WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldDefaultType})},
+			query:   `a\(b:1`,
+			wantErr: true,
+		},
+		{
+			name:    "test default type of exists field isn't identifier",
+			opts:    []func(*SqlConvertor){schema, WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldDefaultType})},
+			query:   `_exists_:a\(b`,
+			wantErr: true,
+		},
+		{
+			name:    "test default type of qualified field",
+			opts:    []func(*SqlConvertor){schema, WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldDefaultType})},
+			query:   "user.name_1:x",
+			wantSQL: "user.name_1 = 'x'",
+		},
+		{
+			name: "test default type of field denied in other case",
+			opts: []func(*SqlConvertor){schema, WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldDefaultType}),
+				WithFieldPolicy(&FieldPolicy{Deny: []string{"ssn"}})},
+			query:   "a:1 AND SSN:1",
+			wantErr: true,
+		},
+		{
+			name: "test dynamic column of range without bounds",
+			opts: []func(*SqlConvertor){schema, WithSQLStyle(PostgreSQL), WithUnknownFieldPolicy(&UnknownFieldPolicy{
+				Mode:   UnknownFieldDynamic,
+				Column: "attrs",
+			})},
+			query:   "a:[* TO *] AND NOT b:[* TO *]",
+			wantSQL: "a IS NOT NULL AND NOT ( attrs ? 'b' )",
+		},
+		{
+			name: "test dynamic column",
+			opts: []func(*SqlConvertor){schema, WithSQLStyle(PostgreSQL), WithUnknownFieldPolicy(&UnknownFieldPolicy{
+				Mode:   UnknownFieldDynamic,
+				Column: "attrs",
+			})},
+			query:   "a:1 AND b:2 AND _exists_:c",
+			wantSQL: "a = '1' AND attrs ->> 'b' = '2' AND attrs ? 'c'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSqlConvertor(tt.opts...).LuceneToSql(tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantSQL, got)
+			}
+		})
+	}
+}
+
+func TestUnknownFieldWarnings(t *testing.T) {
+	cvt := NewSqlConvertor(WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldIgnore}))
+	_, _, err := cvt.resolveField("b")
+	assert.ErrorIs(t, err, errFieldIgnored)
+	assert.Equal(t, []*Warning{{
+		Kind:    UnknownFieldDropped,
+		Clause:  Clause{Offset: -1},
+		Message: "clause on unknown field: b matches nothing",
+	}}, cvt.warnings)
+}
@@ -0,0 +1,122 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zhuliquan/lucene_parser/term"
+)
+
+// WARNING_KIND is the kind of non-fatal warning of conversion, i.e. how clause is interpreted differently from ES.
+type WARNING_KIND int32
+
+const (
+	BoostIgnored          WARNING_KIND = iota // boost (e.g. foo^2) only affects score, it's dropped from predicate
+	FuzzinessDefaulted                        // fuzziness of foo~ is chosen by convertor
+	FuzzyApproximated                         // fuzzy query isn't converted to edit distance
+	TextMatchedByLike                         // text field is matched by LIKE rather than analyzed terms
+	LenientDropped                            // clause whose value doesn't match type of field matches nothing
+	UnknownFieldDropped                       // clause on unknown field matches nothing
+	ShouldClauseDropped                       // should clause is dropped from predicate by must clauses of same level
+	ProximityApproximated                     // proximity phrase of too many alternatives is matched approximately
+	FuzzinessClamped                          // fuzziness of foo~5 is clamped to max edits of lucene
+)
+
+func (k WARNING_KIND) String() string {
+	switch k {
+	case BoostIgnored:
+		return "BoostIgnored"
+	case FuzzinessDefaulted:
+		return "FuzzinessDefaulted"
+	case FuzzinessClamped:
+		return "FuzzinessClamped"
+	case FuzzyApproximated:
+		return "FuzzyApproximated"
+	case TextMatchedByLike:
+		return "TextMatchedByLike"
+	case LenientDropped:
+		return "LenientDropped"
+	case UnknownFieldDropped:
+		return "UnknownFieldDropped"
+	case ShouldClauseDropped:
+		return "ShouldClauseDropped"
+	case ProximityApproximated:
+		return "ProximityApproximated"
+	default:
+		return "Unknown"
+	}
+}
+
+// Warning tells how clause of query is interpreted, it doesn't fail conversion.
+type Warning struct {
+	Kind WARNING_KIND
+	Clause
+	Message string
+}
+
+func (w *Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Kind, w.Message)
+}
+
+// Result is sql of query and the args of placeholders in sql, with warnings of conversion.
+type Result struct {
+	SQL  string
+	Args []interface{}
+	// Score is expression of relevance score if WithScoring is on, e.g. ORDER BY <Score> DESC.
+	// it sums boosts of matched clauses, or ranks of full text search on text fields in WithFullTextRank mode.
+	Score    string
+	Warnings []*Warning
+}
+
+// Convert converts lucene query like LuceneToSqlContext, and returns the warnings of conversion.
+func (c *SqlConvertor) Convert(
+	ctx context.Context, query string, options ...func(s *SqlConvertor),
+) (*Result, error) {
+	return c.luceneToSqlWithFilters(ctx, query, false, options)
+}
+
+// warn adds warning of conversion, field and term of warning are filled by termQueryToSql if they're empty.
+func (c *SqlConvertor) warn(kind WARNING_KIND, format string, args ...interface{}) {
+	c.warnings = append(c.warnings, &Warning{Kind: kind, Clause: Clause{Offset: -1}, Message: fmt.Sprintf(format, args...)})
+}
+
+// setWarningClause fills field and term of warnings which are added from the n-th warning.
+func (c *SqlConvertor) setWarningClause(n int, field, term string) {
+	for _, w := range c.warnings[n:] {
+		if w.Field == "" {
+			w.Field = field
+		}
+		if w.Term == "" {
+			w.Term = term
+		}
+	}
+}
+
+// locateWarnings sets offsets of clauses of warnings in original query, unless they're located already.
+func locateWarnings(warnings []*Warning, locator *clauseLocator) {
+	for _, w := range warnings {
+		if w.Offset == -1 {
+			w.Offset = locator.locate(w.Field, w.Term).start
+		}
+	}
+}
+
+// stripBoost returns copy of term without boost and the boost symbol, because boost only affects score.
+func stripBoost(value *term.Term) (*term.Term, string) {
+	switch {
+	case value.FuzzyTerm != nil && value.FuzzyTerm.BoostSymbol != "":
+		fuzzyTerm := *value.FuzzyTerm
+		fuzzyTerm.BoostSymbol = ""
+		return &term.Term{FuzzyTerm: &fuzzyTerm}, value.FuzzyTerm.BoostSymbol
+	case value.RangeTerm != nil && value.RangeTerm.BoostSymbol != "":
+		rangeTerm := *value.RangeTerm
+		rangeTerm.BoostSymbol = ""
+		return &term.Term{RangeTerm: &rangeTerm}, value.RangeTerm.BoostSymbol
+	case value.TermGroup != nil && value.TermGroup.BoostSymbol != "":
+		termGroup := *value.TermGroup
+		termGroup.BoostSymbol = ""
+		return &term.Term{TermGroup: &termGroup}, value.TermGroup.BoostSymbol
+	default:
+		return value, ""
+	}
+}
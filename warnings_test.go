@@ -0,0 +1,143 @@
+package lucene_to_sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	esMapping "github.com/zhuliquan/es-mapping"
+)
+
+func TestConvertWarnings(t *testing.T) {
+	cvt := NewSqlConvertor(
+		WithSQLStyle(PostgreSQL),
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"name": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+				"body": {
+					Type: esMapping.TEXT_FIELD_TYPE,
+				},
+			},
+		})),
+		WithUnknownFieldPolicy(&UnknownFieldPolicy{Mode: UnknownFieldIgnore}),
+		WithFilter("tenant = ?", 1),
+	)
+	res, err := cvt.Convert(context.Background(), "name:foo^2 AND body:bar AND name:baz~ AND city:x")
+	assert.NoError(t, err)
+	assert.Equal(t, "( name = 'foo' AND body like '%bar%' AND levenshtein(name, 'baz') <= 1 AND 1 = 0 ) AND ( tenant = $1 )", res.SQL)
+	assert.Equal(t, []interface{}{1}, res.Args)
+	assert.Equal(t, []*Warning{
+		{
+			Kind:    BoostIgnored,
+			Clause:  Clause{Field: "name", Term: "foo^2", Offset: 0},
+			Message: "boost: ^2 only affects score, it's ignored",
+		},
+		{
+			Kind:    TextMatchedByLike,
+			Clause:  Clause{Field: "body", Term: "bar", Offset: 15},
+			Message: "text is matched by LIKE without analysis",
+		},
+		{
+			Kind:    FuzzinessDefaulted,
+			Clause:  Clause{Field: "name", Term: "baz~", Offset: 28},
+			Message: "fuzziness of term: baz is 1",
+		},
+		{
+			Kind:    UnknownFieldDropped,
+			Clause:  Clause{Field: "city", Term: "x", Offset: 42},
+			Message: "clause on unknown field: city matches nothing",
+		},
+	}, res.Warnings)
+}
+
+func TestConvertBoostOfTermGroup(t *testing.T) {
+	res, err := NewSqlConvertor(WithSchema(getSchema(&esMapping.Mapping{
+		Properties: map[string]*esMapping.Property{
+			"name": {
+				Type: esMapping.KEYWORD_FIELD_TYPE,
+			},
+		},
+	}))).Convert(context.Background(), "name:(foo OR bar)^3")
+	assert.NoError(t, err)
+	assert.Equal(t, "name IN ('foo', 'bar')", res.SQL)
+	assert.Len(t, res.Warnings, 1)
+	assert.Equal(t, BoostIgnored, res.Warnings[0].Kind)
+	assert.Equal(t, "BoostIgnored: boost: ^3 only affects score, it's ignored", res.Warnings[0].String())
+}
+
+func TestConvertWarningsOfTermWithoutField(t *testing.T) {
+	res, err := NewSqlConvertor(
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"name": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+			},
+		})),
+		WithDefaultFields("name"),
+	).Convert(context.Background(), "foo^2 bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "name = 'foo' OR name = 'bar'", res.SQL)
+	assert.Equal(t, []*Warning{
+		{
+			Kind:    BoostIgnored,
+			Clause:  Clause{Term: "foo^2", Offset: 0},
+			Message: "boost: ^2 only affects score, it's ignored",
+		},
+	}, res.Warnings)
+}
+
+func TestConvertWarningsOfDroppedShoulds(t *testing.T) {
+	res, err := NewSqlConvertor(
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"name": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+			},
+		})),
+	).Convert(context.Background(), "name:a AND (name:b OR name:c) name:z +name:(+x y)")
+	assert.NoError(t, err)
+	assert.Equal(t, "name = 'a' AND ( name = 'b' OR name = 'c' ) AND name = 'x'", res.SQL)
+	assert.Equal(t, []*Warning{
+		{
+			Kind:    ShouldClauseDropped,
+			Clause:  Clause{Field: "name", Term: "z", Offset: 30},
+			Message: "should clause: name:z is dropped by required clauses, it only affects score",
+		},
+		{
+			Kind:    ShouldClauseDropped,
+			Clause:  Clause{Field: "name", Term: "y", Offset: 47},
+			Message: "should clause: y is dropped by required clauses, it only affects score",
+		},
+	}, res.Warnings)
+}
+
+func TestConvertWarningsOfClampedFuzziness(t *testing.T) {
+	res, err := NewSqlConvertor(
+		WithSQLStyle(PostgreSQL),
+		WithSchema(getSchema(&esMapping.Mapping{
+			Properties: map[string]*esMapping.Property{
+				"name": {
+					Type: esMapping.KEYWORD_FIELD_TYPE,
+				},
+			},
+		})),
+	).Convert(context.Background(), "name:jon~5 OR name:jonathan~0.5 OR name:bob~0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, "levenshtein(name, 'jon') <= 2 OR levenshtein(name, 'jonathan') <= 2 OR levenshtein(name, 'bob') <= 1", res.SQL)
+	assert.Equal(t, []*Warning{
+		{
+			Kind:    FuzzinessClamped,
+			Clause:  Clause{Field: "name", Term: "jon~5", Offset: 0},
+			Message: "fuzziness: 5 of term: jon is clamped to 2 edits",
+		},
+		{
+			Kind:    FuzzinessClamped,
+			Clause:  Clause{Field: "name", Term: "jonathan~0.5", Offset: 14},
+			Message: "fuzziness: 0.5 of term: jonathan is clamped to 2 edits",
+		},
+	}, res.Warnings)
+}